@@ -0,0 +1,110 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ModelVersion is a single device version along with the details of its parent model.
+type ModelVersion struct {
+	Model        string `json:"model"`
+	Manufacturer string `json:"manufacturer"`
+	Id           string `json:"id"`
+	Version
+}
+
+// HasTag returns whether the version has been tagged with the given tag.
+func (v ModelVersion) HasTag(tag string) bool {
+	for _, t := range v.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+type ModelVersions []ModelVersion
+
+func (m ModelVersions) Len() int           { return len(m) }
+func (m ModelVersions) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m ModelVersions) Less(i, j int) bool { return m[i].Name < m[j].Name }
+
+// ModelCatalog indexes models and their versions, versions are referenced by their names.
+type ModelCatalog struct {
+	models   map[string]Model
+	versions map[string]ModelVersion
+}
+
+// NewModelCatalog builds a catalog from a list of models, model and version names are expected to be unique.
+func NewModelCatalog(models []Model) (*ModelCatalog, error) {
+	c := ModelCatalog{
+		models:   make(map[string]Model),
+		versions: make(map[string]ModelVersion),
+	}
+
+	for _, m := range models {
+		if _, ok := c.models[m.Name]; ok {
+			return nil, fmt.Errorf("duplicate model: %s", m.Name)
+		}
+		c.models[m.Name] = m
+
+		for k, v := range m.Versions {
+			if o, ok := c.versions[v.Name]; ok {
+				return nil, fmt.Errorf("duplicate model version: %s (%s and %s)", v.Name, o.Model, m.Name)
+			}
+			c.versions[v.Name] = ModelVersion{
+				Model:        m.Name,
+				Manufacturer: m.Manufacturer,
+				Id:           k,
+				Version:      v,
+			}
+		}
+	}
+
+	return &c, nil
+}
+
+// Model returns the named model.
+func (c *ModelCatalog) Model(name string) (*Model, error) {
+	m, ok := c.models[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown model: %s", name)
+	}
+	return &m, nil
+}
+
+// Resolve finds the model version referenced by an install or device model name.
+func (c *ModelCatalog) Resolve(name string) (*ModelVersion, error) {
+	v, ok := c.versions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown model version: %s", name)
+	}
+	return &v, nil
+}
+
+// Versions returns all catalogued versions, sorted by name.
+func (c *ModelCatalog) Versions() ModelVersions {
+	return c.Filter(func(ModelVersion) bool { return true })
+}
+
+// Filter returns the versions which match the given function, sorted by name.
+func (c *ModelCatalog) Filter(fn func(ModelVersion) bool) ModelVersions {
+	var vv ModelVersions
+	for _, v := range c.versions {
+		if fn(v) {
+			vv = append(vv, v)
+		}
+	}
+	sort.Sort(vv)
+	return vv
+}
+
+// Type returns all versions of the given generic type.
+func (c *ModelCatalog) Type(t string) ModelVersions {
+	return c.Filter(func(v ModelVersion) bool { return v.Type == t })
+}
+
+// Tagged returns all versions with the given tag.
+func (c *ModelCatalog) Tagged(tag string) ModelVersions {
+	return c.Filter(func(v ModelVersion) bool { return v.HasTag(tag) })
+}
@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestModelCatalog(t *testing.T) {
+
+	c, err := NewModelCatalog([]Model{testModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check model catalog queries.")
+	{
+		if n := len(c.Versions()); n != 3 {
+			t.Errorf("catalog versions mismatch: %d != 3", n)
+		}
+		if v := c.Type("Model Type B"); len(v) != 1 || v[0].Name != "Model B" {
+			t.Errorf("catalog type query mismatch: %v", v)
+		}
+		if v := c.Tagged("C"); len(v) != 1 || v[0].Id != "model_b" {
+			t.Errorf("catalog tag query mismatch: %v", v)
+		}
+		if v := c.Tagged("D"); len(v) != 0 {
+			t.Errorf("catalog tag query mismatch: %v", v)
+		}
+	}
+
+	t.Log("Check model catalog resolving.")
+	{
+		v, err := c.Resolve("Model A")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Manufacturer != testModel.Manufacturer || v.Model != testModel.Name {
+			t.Errorf("catalog resolve mismatch: %v", v)
+		}
+		if _, err := c.Resolve("Model Z"); err == nil {
+			t.Error("catalog resolve should fail for unknown models")
+		}
+		if _, err := c.Model("Unknown Model"); err == nil {
+			t.Error("catalog model should fail for unknown models")
+		}
+	}
+
+	t.Log("Check model catalog duplicates.")
+	{
+		if _, err := NewModelCatalog([]Model{testModel, testModel}); err == nil {
+			t.Error("catalog should fail for duplicate models")
+		}
+	}
+}
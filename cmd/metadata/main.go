@@ -0,0 +1,681 @@
+// metadata is a command line tool for querying and validating a metadata tree.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ozym/metadata"
+)
+
+const usage = `usage: metadata [options] <command> [arguments]
+
+commands:
+    validate                  check the consistency of the metadata tree
+    list locations|networks|providers|models
+                              list the loaded metadata entities
+    show location <id>        show the details of a single location
+    sheet <id>                print a site access field sheet for a location
+    report [-html] [-at <time>] <id>
+                              print a markdown, or html, site report for a location
+    installs [-at <time>]     list the equipment installed at a given time
+    ip lookup <addr>          find where an IP address is used
+    links                     report the path profile and link budget of each radio link
+    reuse [-radius <km>]      report nearby radio paths sharing a frequency key and polarity
+    outage <name>...          report the locations affected if the given providers or services go down
+    contracts [-days <n>]     list the service contracts ending within the given number of days
+    power [-at <time>] [-risk]
+                              report the power budget of each site, or only the sites at risk
+    costs providers|locations report the total monthly cost of the active services
+    export <format> [file]    export the whole tree, formats: json, sqlite <file>
+    diff <dir>                report changes from the tree found in dir
+    history <key>             report the git history of a location, device or serial number
+    fmt [-w]                  list, or rewrite, files not in their canonical form
+
+options:
+`
+
+type command struct {
+	tree   *metadata.Tree
+	json   bool
+	output io.Writer
+}
+
+func main() {
+
+	var base string
+	flag.StringVar(&base, "base", ".", "metadata root directory")
+
+	var asJSON bool
+	flag.BoolVar(&asJSON, "json", false, "output json rather than a table")
+
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tree, err := metadata.LoadTree(base)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load metadata from %s: %v\n", base, err)
+		os.Exit(1)
+	}
+
+	cmd := command{
+		tree:   tree,
+		json:   asJSON,
+		output: os.Stdout,
+	}
+
+	args := flag.Args()
+	switch args[0] {
+	case "validate":
+		err = cmd.validate()
+	case "list":
+		err = cmd.list(args[1:])
+	case "show":
+		err = cmd.show(args[1:])
+	case "sheet":
+		err = cmd.sheet(args[1:])
+	case "report":
+		err = cmd.report(args[1:])
+	case "installs":
+		err = cmd.installs(args[1:])
+	case "ip":
+		err = cmd.ip(args[1:])
+	case "links":
+		err = cmd.links()
+	case "reuse":
+		err = cmd.reuse(args[1:])
+	case "outage":
+		err = cmd.outage(args[1:])
+	case "contracts":
+		err = cmd.contracts(args[1:])
+	case "power":
+		err = cmd.power(args[1:])
+	case "costs":
+		err = cmd.costs(args[1:])
+	case "export":
+		err = cmd.export(args[1:])
+	case "diff":
+		err = cmd.diff(args[1:])
+	case "history":
+		err = cmd.history(base, args[1:])
+	case "fmt":
+		err = cmd.format(base, args[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "metadata %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+}
+
+// encode writes out either the json encoded value or a table of rows.
+func (c command) encode(v interface{}, header []string, rows [][]string) error {
+	if c.json {
+		enc := json.NewEncoder(c.output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(c.output, 0, 4, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+	}
+	for _, r := range rows {
+		fmt.Fprintln(w, strings.Join(r, "\t"))
+	}
+	return w.Flush()
+}
+
+func (c command) validate() error {
+	errs := c.tree.Validate()
+
+	var problems []string
+	var rows [][]string
+	for _, e := range errs {
+		problems = append(problems, e.Error())
+		rows = append(rows, []string{e.Error()})
+	}
+	if err := c.encode(problems, nil, rows); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("found %d problems", len(errs))
+	}
+	return nil
+}
+
+func (c command) list(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one of locations, networks, providers or models")
+	}
+
+	var rows [][]string
+	switch args[0] {
+	case "locations":
+		for _, l := range c.tree.Locations {
+			rows = append(rows, []string{l.Id, l.Name, latlon(l.Latitude), latlon(l.Longitude), strings.Join(l.Tags, ",")})
+		}
+		return c.encode(c.tree.Locations, []string{"ID", "NAME", "LATITUDE", "LONGITUDE", "TAGS"}, rows)
+	case "networks":
+		for _, n := range c.tree.Networks {
+			rows = append(rows, []string{n.Location, str(n.Name), ipnet(n.Runnet), fmt.Sprintf("%d", len(n.Devices))})
+		}
+		return c.encode(c.tree.Networks, []string{"LOCATION", "NAME", "RUNNET", "DEVICES"}, rows)
+	case "providers":
+		for _, p := range c.tree.Providers {
+			rows = append(rows, []string{p.Name, fmt.Sprintf("%d", len(p.Services)), fmt.Sprintf("%d", len(p.Ranges))})
+		}
+		return c.encode(c.tree.Providers, []string{"NAME", "SERVICES", "RANGES"}, rows)
+	case "models":
+		catalog, err := metadata.NewModelCatalog(c.tree.Models)
+		if err != nil {
+			return err
+		}
+		versions := catalog.Versions()
+		for _, v := range versions {
+			rows = append(rows, []string{v.Name, v.Type, v.Model, v.Manufacturer, strings.Join(v.Tags, ",")})
+		}
+		return c.encode(versions, []string{"NAME", "TYPE", "MODEL", "MANUFACTURER", "TAGS"}, rows)
+	default:
+		return fmt.Errorf("unknown list: %s", args[0])
+	}
+}
+
+func (c command) show(args []string) error {
+	if len(args) != 2 || args[0] != "location" {
+		return fmt.Errorf("expected location <id>")
+	}
+
+	l, ok := c.tree.Location(args[1])
+	if !ok {
+		return fmt.Errorf("unknown location: %s", args[1])
+	}
+	n, _ := c.tree.Network(l.Id)
+
+	if c.json {
+		return c.encode(struct {
+			Location *metadata.Location `json:"location"`
+			Network  *metadata.Network  `json:"network,omitempty"`
+		}{l, n}, nil, nil)
+	}
+
+	rows := [][]string{
+		{"id", l.Id},
+		{"name", l.Name},
+		{"latitude", latlon(l.Latitude)},
+		{"longitude", latlon(l.Longitude)},
+		{"datum", str(l.Datum)},
+		{"elevation", metres(l.Elevation)},
+		{"ground", metres(l.Ground)},
+		{"services", strings.Join(l.Services, ", ")},
+		{"tags", strings.Join(l.Tags, ", ")},
+	}
+	if g, ok := l.NZTM(); ok {
+		rows = append(rows, []string{"nztm", fmt.Sprintf("%.1f %.1f", g.Easting, g.Northing)})
+	}
+	for _, k := range l.Links {
+		rows = append(rows, []string{"link", strings.TrimSpace(strings.Join([]string{k.Id, str(k.Role), str(k.Key), str(k.Polarity)}, " "))})
+	}
+	if n != nil {
+		rows = append(rows, []string{"runnet", ipnet(n.Runnet)})
+		for _, d := range n.Devices {
+			var a string
+			if d.Address != nil {
+				a = d.Address.String()
+			}
+			rows = append(rows, []string{"device", strings.TrimSpace(strings.Join([]string{d.Name, d.Model, a}, " "))})
+		}
+	}
+
+	return c.encode(nil, nil, rows)
+}
+
+func (c command) sheet(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a location id")
+	}
+
+	l, ok := c.tree.Location(args[0])
+	if !ok {
+		return fmt.Errorf("unknown location: %s", args[0])
+	}
+
+	sheet, err := l.FieldSheet(time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(c.output, sheet)
+	return err
+}
+
+func (c command) report(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+
+	var html bool
+	fs.BoolVar(&html, "html", false, "output a printable html page rather than markdown")
+
+	var at string
+	fs.StringVar(&at, "at", metadata.DateTime(time.Now().UTC()), "report time")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a location id")
+	}
+
+	t, err := metadata.ParseTime(at)
+	if err != nil {
+		return err
+	}
+
+	report, err := c.tree.SiteReport(fs.Arg(0), t)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return c.encode(report, nil, nil)
+	}
+
+	var text string
+	switch {
+	case html:
+		text, err = report.HTML()
+	default:
+		text, err = report.Markdown()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(c.output, text)
+	return err
+}
+
+func (c command) installs(args []string) error {
+	fs := flag.NewFlagSet("installs", flag.ContinueOnError)
+
+	var at string
+	fs.StringVar(&at, "at", metadata.DateTime(time.Now().UTC()), "installation time")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := metadata.ParseTime(at)
+	if err != nil {
+		return err
+	}
+
+	installed := c.tree.Installed(t)
+
+	var rows [][]string
+	for _, i := range installed {
+		rows = append(rows, []string{i.Location, i.Model, i.Serial, start(i.Start), metadata.DateTime(i.Stop)})
+	}
+
+	return c.encode(installed, []string{"LOCATION", "MODEL", "SERIAL", "START", "STOP"}, rows)
+}
+
+func (c command) ip(args []string) error {
+	if len(args) != 2 || args[0] != "lookup" {
+		return fmt.Errorf("expected lookup <addr>")
+	}
+
+	ip := net.ParseIP(args[1])
+	if ip == nil {
+		a, _, err := net.ParseCIDR(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", args[1])
+		}
+		ip = a
+	}
+
+	matches := c.tree.LookupIP(ip)
+
+	var rows [][]string
+	for _, m := range matches {
+		rows = append(rows, []string{m.Kind, m.Name, m.Location, m.Network})
+	}
+
+	return c.encode(matches, []string{"KIND", "NAME", "LOCATION", "NETWORK"}, rows)
+}
+
+func (c command) links() error {
+	catalog, err := metadata.NewModelCatalog(c.tree.Models)
+	if err != nil {
+		return err
+	}
+
+	keys, err := metadata.NewFrequencyKeys(c.tree.Frequencies)
+	if err != nil {
+		return err
+	}
+
+	budgets := metadata.CalculateLinkBudgets(c.tree.Locations, catalog, c.tree.Radios, c.tree.Networks, keys.LinkFrequency)
+
+	var rows [][]string
+	for _, b := range budgets {
+		rows = append(rows, []string{b.Location, b.Target, str(b.Key), decimal(b.Distance, 2), decimal(b.Bearing, 1),
+			decimal(b.BackBearing, 1), decimal(b.Frequency, 1), decimal(b.PathLoss, 1), decimal(b.Fresnel, 1),
+			decimal(b.Received, 1), decimal(b.Margin, 1)})
+	}
+
+	header := []string{"LOCATION", "TARGET", "KEY", "KM", "BEARING", "BACK", "MHZ", "FSPL", "FRESNEL", "RX", "MARGIN"}
+	return c.encode(budgets, header, rows)
+}
+
+func (c command) reuse(args []string) error {
+	fs := flag.NewFlagSet("reuse", flag.ContinueOnError)
+
+	var radius float64
+	fs.Float64Var(&radius, "radius", 50.0, "distance in km between path endpoints to check")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	reuses := metadata.CheckFrequencyReuse(c.tree.Locations, radius)
+
+	var rows [][]string
+	for _, r := range reuses {
+		rows = append(rows, []string{r.Key, r.Polarity, r.Path[0] + "-" + r.Path[1], r.Other[0] + "-" + r.Other[1],
+			strconv.FormatFloat(r.Distance, 'f', 1, 64)})
+	}
+	if err := c.encode(reuses, []string{"KEY", "POLARITY", "PATH", "OTHER", "KM"}, rows); err != nil {
+		return err
+	}
+	if len(reuses) > 0 {
+		return fmt.Errorf("found %d frequency reuses", len(reuses))
+	}
+
+	return nil
+}
+
+func (c command) outage(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("expected a provider name or service label")
+	}
+
+	impacts, err := c.tree.Outage(args...)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, i := range impacts {
+		status := "degraded"
+		if i.Isolated {
+			status = "isolated"
+		}
+		rows = append(rows, []string{i.Location, status, strings.Join(i.Services, ","), strings.Join(i.Devices, ",")})
+	}
+
+	return c.encode(impacts, []string{"LOCATION", "STATUS", "SERVICES", "DEVICES"}, rows)
+}
+
+func (c command) contracts(args []string) error {
+	fs := flag.NewFlagSet("contracts", flag.ContinueOnError)
+
+	var days int
+	fs.IntVar(&days, "days", 90, "number of days ahead to check")
+
+	var at string
+	fs.StringVar(&at, "at", metadata.DateTime(time.Now().UTC()), "check time")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := metadata.ParseTime(at)
+	if err != nil {
+		return err
+	}
+
+	expiries := c.tree.ExpiringContracts(t, days)
+
+	var rows [][]string
+	for _, e := range expiries {
+		var notice string
+		if e.NoticeBy != nil {
+			notice = metadata.DateTime(*e.NoticeBy)
+		}
+		rows = append(rows, []string{e.Provider, e.Service, metadata.Default("", e.Circuit), metadata.DateTime(e.End), strconv.Itoa(e.Days), notice})
+	}
+
+	return c.encode(expiries, []string{"PROVIDER", "SERVICE", "CIRCUIT", "END", "DAYS", "NOTICE"}, rows)
+}
+
+func (c command) power(args []string) error {
+	fs := flag.NewFlagSet("power", flag.ContinueOnError)
+
+	var at string
+	fs.StringVar(&at, "at", metadata.DateTime(time.Now().UTC()), "installation time")
+
+	var risk bool
+	fs.BoolVar(&risk, "risk", false, "only report sites at risk")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := metadata.ParseTime(at)
+	if err != nil {
+		return err
+	}
+
+	budgets := c.tree.PowerBudgets(t)
+	if risk {
+		budgets = budgets.AtRisk()
+	}
+
+	var rows [][]string
+	for _, b := range budgets {
+		rows = append(rows, []string{b.Location, strconv.FormatFloat(b.Load, 'f', 1, 64),
+			strconv.FormatFloat(b.Consumption(), 'f', 1, 64), strconv.FormatFloat(b.Generation(), 'f', 1, 64),
+			strconv.FormatFloat(b.Reserve(), 'f', 1, 64), strconv.FormatBool(b.AtRisk()), strings.Join(b.Unknown, ", ")})
+	}
+
+	return c.encode(budgets, []string{"LOCATION", "LOAD", "CONSUMPTION", "GENERATION", "RESERVE", "RISK", "UNKNOWN"}, rows)
+}
+
+func (c command) costs(args []string) error {
+	fs := flag.NewFlagSet("costs", flag.ContinueOnError)
+
+	var at string
+	fs.StringVar(&at, "at", metadata.DateTime(time.Now().UTC()), "cost time")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected providers or locations")
+	}
+
+	t, err := metadata.ParseTime(at)
+	if err != nil {
+		return err
+	}
+
+	var costs metadata.ServiceCosts
+	var name string
+	switch fs.Arg(0) {
+	case "providers":
+		costs, name = c.tree.ProviderCosts(t), "PROVIDER"
+	case "locations":
+		costs, name = c.tree.LocationCosts(t), "LOCATION"
+	default:
+		return fmt.Errorf("unknown cost report: %s", fs.Arg(0))
+	}
+
+	var rows [][]string
+	for _, v := range costs {
+		rows = append(rows, []string{v.Name, strconv.Itoa(v.Services), strconv.FormatFloat(v.Monthly, 'f', 2, 64)})
+	}
+
+	return c.encode(costs, []string{name, "SERVICES", "MONTHLY"}, rows)
+}
+
+func (c command) export(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("expected an export format")
+	}
+
+	switch args[0] {
+	case "json":
+		enc := json.NewEncoder(c.output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c.tree)
+	case "sqlite":
+		if len(args) != 2 {
+			return fmt.Errorf("expected an sqlite database file")
+		}
+		return metadata.ExportSQLite(args[1], c.tree)
+	default:
+		return fmt.Errorf("unknown export format: %s", args[0])
+	}
+}
+
+func (c command) diff(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a metadata directory to compare against")
+	}
+
+	old, err := metadata.LoadTree(args[0])
+	if err != nil {
+		return err
+	}
+
+	changes := metadata.DiffTrees(old, c.tree)
+	if c.json {
+		b, err := changes.JSON()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.output, string(b))
+		return err
+	}
+
+	if len(changes) > 0 {
+		_, err = fmt.Fprintln(c.output, changes.String())
+	}
+	return err
+}
+
+func (c command) history(repo string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected an entity key")
+	}
+
+	history, err := metadata.History(repo, args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return c.encode(history, nil, nil)
+	}
+
+	for _, h := range history {
+		fmt.Fprintf(c.output, "%s %s %s: %s\n", h.Commit[:8], metadata.DateTime(h.Time), h.Author, h.Subject)
+		for _, l := range strings.Split(h.Changes.String(), "\n") {
+			fmt.Fprintf(c.output, "    %s\n", l)
+		}
+	}
+
+	return nil
+}
+
+func (c command) format(base string, args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+
+	var write bool
+	fs.BoolVar(&write, "w", false, "rewrite files in their canonical form")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	changed, err := metadata.Format(base, write)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for _, p := range changed {
+		rows = append(rows, []string{p})
+	}
+	if err := c.encode(changed, nil, rows); err != nil {
+		return err
+	}
+	if len(changed) > 0 && !write {
+		return fmt.Errorf("found %d files not in canonical form", len(changed))
+	}
+
+	return nil
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// start formats an installation start time, radio installs have none.
+func start(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return metadata.DateTime(t)
+}
+
+func decimal(f *float64, places int) string {
+	if f == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*f, 'f', places, 64)
+}
+
+func metres(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return metadata.Float(f) + " m"
+}
+
+func latlon(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return metadata.LatLon(f)
+}
+
+func ipnet(n *metadata.IPNetwork) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ozym/metadata"
+)
+
+func TestCommands(t *testing.T) {
+
+	tree, err := metadata.LoadTree("../../testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := func(path string) string {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	var tests = []struct {
+		name     string
+		json     bool
+		run      func(c command) error
+		expected string
+	}{
+		{
+			name:     "export json",
+			run:      func(c command) error { return c.export([]string{"json"}) },
+			expected: golden("testdata/export.json"),
+		},
+		{
+			name: "installs",
+			run:  func(c command) error { return c.installs([]string{"-at", "2016-01-02T00:00:00Z"}) },
+			expected: `LOCATION        MODEL           SERIAL           START                 STOP
+ABCD            Model           Serial #2        2010-01-01T00:00:00Z  9999-01-01T00:00:00Z
+EFGH            Model           Serial #2        2010-01-01T00:00:00Z  9999-01-01T00:00:00Z
+Somewhere       Radio Model #1  Radio Serial #1  -                     9999-01-01T00:00:00Z
+Somewhere Else  Radio Model #1  Radio Serial #2  -                     9999-01-01T00:00:00Z
+`,
+		},
+		{
+			name: "installs before any equipment",
+			run:  func(c command) error { return c.installs([]string{"-at", "2000-01-02T00:00:00Z"}) },
+			expected: `LOCATION        MODEL           SERIAL           START  STOP
+Somewhere       Radio Model #1  Radio Serial #1  -      9999-01-01T00:00:00Z
+Somewhere Else  Radio Model #1  Radio Serial #2  -      9999-01-01T00:00:00Z
+`,
+		},
+		{
+			name:     "report",
+			run:      func(c command) error { return c.report([]string{"-at", "2016-01-02T00:00:00Z", "location"}) },
+			expected: golden("testdata/report.md"),
+		},
+		{
+			name: "provider costs",
+			run:  func(c command) error { return c.costs([]string{"-at", "2016-01-02T00:00:00Z", "providers"}) },
+			expected: `PROVIDER          SERVICES  MONTHLY
+Example Provider  1         450.50
+`,
+		},
+		{
+			name: "provider costs as json",
+			json: true,
+			run:  func(c command) error { return c.costs([]string{"-at", "2016-01-02T00:00:00Z", "providers"}) },
+			expected: `[
+  {
+    "name": "Example Provider",
+    "services": 1,
+    "monthly": 450.5
+  }
+]
+`,
+		},
+		{
+			name:     "provider costs after the contract",
+			run:      func(c command) error { return c.costs([]string{"-at", "2019-01-02T00:00:00Z", "providers"}) },
+			expected: "PROVIDER  SERVICES  MONTHLY\n",
+		},
+		{
+			name: "power",
+			run:  func(c command) error { return c.power([]string{"-at", "2016-01-02T00:00:00Z"}) },
+			expected: `LOCATION  LOAD  CONSUMPTION  GENERATION  RESERVE  RISK   UNKNOWN
+location  0.0   0.0          420.0       0.0      false  
+`,
+		},
+		{
+			name:     "power sites at risk",
+			run:      func(c command) error { return c.power([]string{"-at", "2016-01-02T00:00:00Z", "-risk"}) },
+			expected: "LOCATION  LOAD  CONSUMPTION  GENERATION  RESERVE  RISK  UNKNOWN\n",
+		},
+		{
+			name:     "location costs",
+			run:      func(c command) error { return c.costs([]string{"-at", "2016-01-02T00:00:00Z", "locations"}) },
+			expected: "LOCATION  SERVICES  MONTHLY\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Check %s command output.", test.name)
+		{
+			var out bytes.Buffer
+			if err := test.run(command{tree: tree, json: test.json, output: &out}); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if s := out.String(); s != test.expected {
+				t.Errorf("%s output mismatch: [\n%s\n]", test.name, metadata.SimpleDiff(s, test.expected))
+			}
+		}
+	}
+
+	t.Log("Check command argument errors.")
+	{
+		c := command{tree: tree, output: ioutil.Discard}
+		for _, err := range []error{
+			c.export(nil),
+			c.export([]string{"xml"}),
+			c.costs([]string{"services"}),
+			c.report([]string{"unknown"}),
+		} {
+			if err == nil {
+				t.Error("expected a command error")
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package metadata
+
+import (
+	"sort"
+	"time"
+)
+
+// Active returns whether the service contract is in place at the given time, services without contract
+// dates are assumed to be always active.
+func (s Service) Active(at time.Time) bool {
+	if s.Start != nil && s.Start.After(at) {
+		return false
+	}
+	if s.End != nil && !s.End.After(at) {
+		return false
+	}
+	return true
+}
+
+// ContractExpiry describes a service contract which is due to end.
+type ContractExpiry struct {
+	Provider string     `json:"provider"`
+	Service  string     `json:"service"`
+	Circuit  *string    `json:"circuit,omitempty"`
+	End      time.Time  `json:"end"`
+	Days     int        `json:"days"`
+	NoticeBy *time.Time `json:"notice_by,omitempty"`
+}
+
+type ContractExpiries []ContractExpiry
+
+func (c ContractExpiries) Len() int      { return len(c) }
+func (c ContractExpiries) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c ContractExpiries) Less(i, j int) bool {
+	switch {
+	case !c[i].End.Equal(c[j].End):
+		return c[i].End.Before(c[j].End)
+	case c[i].Provider != c[j].Provider:
+		return c[i].Provider < c[j].Provider
+	default:
+		return c[i].Service < c[j].Service
+	}
+}
+
+// ExpiringContracts returns the service contracts ending within the given number of days, sorted by end
+// time. The date by which renewal notice should be given is included when the notice period is known.
+func (t *Tree) ExpiringContracts(at time.Time, days int) ContractExpiries {
+	until := at.AddDate(0, 0, days)
+
+	var expiries ContractExpiries
+	for _, p := range t.Providers {
+		for _, s := range p.Services {
+			if s.End == nil || s.End.Before(at) || s.End.After(until) {
+				continue
+			}
+			e := ContractExpiry{
+				Provider: p.Name,
+				Service:  s.Name,
+				Circuit:  s.Circuit,
+				End:      *s.End,
+				Days:     int(s.End.Sub(at).Hours() / 24.0),
+			}
+			if s.RenewalNotice != nil {
+				notice := s.End.AddDate(0, 0, -*s.RenewalNotice)
+				e.NoticeBy = &notice
+			}
+			expiries = append(expiries, e)
+		}
+	}
+
+	sort.Sort(expiries)
+
+	return expiries
+}
+
+// ServiceCost is the total monthly cost of the active services of a provider or location.
+type ServiceCost struct {
+	Name     string  `json:"name"`
+	Services int     `json:"services"`
+	Monthly  float64 `json:"monthly"`
+}
+
+type ServiceCosts []ServiceCost
+
+func (c ServiceCosts) Len() int           { return len(c) }
+func (c ServiceCosts) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c ServiceCosts) Less(i, j int) bool { return c[i].Name < c[j].Name }
+
+// ProviderCosts totals the monthly cost of each provider's services active at the given time.
+func (t *Tree) ProviderCosts(at time.Time) ServiceCosts {
+	var costs ServiceCosts
+	for _, p := range t.Providers {
+		c := ServiceCost{Name: p.Name}
+		for _, s := range p.Services {
+			if s.MonthlyCost == nil || !s.Active(at) {
+				continue
+			}
+			c.Services++
+			c.Monthly += *s.MonthlyCost
+		}
+		if c.Services > 0 {
+			costs = append(costs, c)
+		}
+	}
+
+	sort.Sort(costs)
+
+	return costs
+}
+
+// LocationCosts totals the monthly cost of each location's services active at the given time. The cost
+// of a service used by more than one location is shared evenly between them. Duplicate service labels
+// are left to validation, the cost of the first service offered is used.
+func (t *Tree) LocationCosts(at time.Time) ServiceCosts {
+	index, _ := NewServiceIndex(t.Providers)
+
+	users := make(map[string]int)
+	for _, l := range t.Locations {
+		for _, s := range l.Services {
+			users[s]++
+		}
+	}
+
+	var costs ServiceCosts
+	for _, l := range t.Locations {
+		c := ServiceCost{Name: l.Id}
+		for _, s := range l.Services {
+			v, ok := index.Lookup(s)
+			if !ok || v.MonthlyCost == nil || !v.Active(at) {
+				continue
+			}
+			c.Services++
+			c.Monthly += *v.MonthlyCost / float64(users[s])
+		}
+		if c.Services > 0 {
+			costs = append(costs, c)
+		}
+	}
+
+	sort.Sort(costs)
+
+	return costs
+}
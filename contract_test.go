@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTree_Contracts(t *testing.T) {
+
+	date := func(s string) *time.Time {
+		v := MustParseTime(s)
+		return &v
+	}
+	cost := func(f float64) *float64 { return &f }
+
+	tree := &Tree{
+		Providers: []Provider{
+			{Name: "Provider 1", Services: []Service{
+				{Name: "S1", End: date("2016-02-01T00:00:00Z"), RenewalNotice: &[]int{30}[0], MonthlyCost: cost(100.0)},
+				{Name: "S2", Start: date("2015-01-01T00:00:00Z"), End: date("2017-01-01T00:00:00Z"), MonthlyCost: cost(50.0)},
+				{Name: "S3", Start: date("2016-06-01T00:00:00Z"), MonthlyCost: cost(25.0)},
+			}},
+			{Name: "Provider 2", Services: []Service{
+				{Name: "S4", End: date("2016-01-20T00:00:00Z"), MonthlyCost: cost(80.0)},
+				{Name: "S5", End: date("2015-12-01T00:00:00Z"), MonthlyCost: cost(10.0)},
+				{Name: "S6"},
+			}},
+		},
+		Locations: []Location{
+			{Id: "A", Services: []string{"S1", "S2"}},
+			{Id: "B", Services: []string{"S2", "S3", "S4"}},
+			{Id: "C", Services: []string{"S6", "S7"}},
+		},
+	}
+	at := MustParseTime("2016-01-02T00:00:00Z")
+
+	t.Log("Check active service contracts.")
+	{
+		var active []string
+		for _, s := range append(tree.Providers[0].Services, tree.Providers[1].Services...) {
+			if s.Active(at) {
+				active = append(active, s.Name)
+			}
+		}
+		if s := fmt.Sprint(active); s != "[S1 S2 S4 S6]" {
+			t.Errorf("active services mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check expiring service contracts.")
+	{
+		expiries := tree.ExpiringContracts(at, 60)
+		if len(expiries) != 2 {
+			t.Fatalf("expiring contracts mismatch: %v", expiries)
+		}
+		if e := expiries[0]; e.Service != "S4" || e.Days != 18 || e.NoticeBy != nil {
+			t.Errorf("expiring contract mismatch: %v", e)
+		}
+		if e := expiries[1]; e.Service != "S1" || e.Days != 30 || e.NoticeBy == nil || !e.NoticeBy.Equal(MustParseTime("2016-01-02T00:00:00Z")) {
+			t.Errorf("expiring contract mismatch: %v", e)
+		}
+		if expiries := tree.ExpiringContracts(at, 7); len(expiries) != 0 {
+			t.Errorf("unexpected expiring contracts: %v", expiries)
+		}
+	}
+
+	t.Log("Check monthly costs per provider.")
+	{
+		if s := fmt.Sprint(tree.ProviderCosts(at)); s != "[{Provider 1 2 150} {Provider 2 1 80}]" {
+			t.Errorf("provider costs mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check monthly costs per location.")
+	{
+		if s := fmt.Sprint(tree.LocationCosts(at)); s != "[{A 2 125} {B 2 105}]" {
+			t.Errorf("location costs mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check monthly costs per location with duplicate services.")
+	{
+		dup := *tree
+		dup.Providers = append(append([]Provider{}, tree.Providers...), Provider{Name: "Provider 3", Services: []Service{
+			{Name: "S1", MonthlyCost: cost(1000.0)},
+		}})
+		if s := fmt.Sprint(dup.LocationCosts(at)); s != "[{A 2 125} {B 2 105}]" {
+			t.Errorf("duplicate location costs mismatch: %s", s)
+		}
+	}
+}
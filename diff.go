@@ -0,0 +1,270 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Change actions.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// FieldChange records the old and new values of a changed field, values are JSON encoded.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// Change describes a single added, removed or changed metadata entity keyed by its natural identifier.
+type Change struct {
+	Kind   string        `json:"kind"`
+	Key    string        `json:"key"`
+	Action string        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+func (c Change) String() string {
+	var s []string
+
+	switch c.Action {
+	case Added:
+		s = append(s, fmt.Sprintf("+ %s %s", c.Kind, c.Key))
+	case Removed:
+		s = append(s, fmt.Sprintf("- %s %s", c.Kind, c.Key))
+	default:
+		s = append(s, fmt.Sprintf("~ %s %s", c.Kind, c.Key))
+	}
+	for _, f := range c.Fields {
+		s = append(s, fmt.Sprintf("    %s: %s -> %s", f.Field, f.Old, f.New))
+	}
+
+	return strings.Join(s, "\n")
+}
+
+type Changes []Change
+
+// String returns a human readable change report.
+func (c Changes) String() string {
+	var s []string
+	for _, v := range c {
+		s = append(s, v.String())
+	}
+	return strings.Join(s, "\n")
+}
+
+// JSON returns a JSON encoded change report.
+func (c Changes) JSON() ([]byte, error) {
+	return json.MarshalIndent(c, "", "  ")
+}
+
+// DiffTrees compares two metadata tree snapshots and reports the added, removed and changed locations,
+// networks, devices, addresses, services, frequency keys and list rows.
+func DiffTrees(old, tree *Tree) Changes {
+	var changes Changes
+
+	changes = append(changes, diffLocations(old, tree)...)
+	changes = append(changes, diffNetworks(old, tree)...)
+	changes = append(changes, diffDevices(old, tree)...)
+	changes = append(changes, diffAddresses(old, tree)...)
+	changes = append(changes, diffServices(old, tree)...)
+	changes = append(changes, diffFrequencies(old, tree)...)
+
+	changes = append(changes, diffList("asset", old.Assets, tree.Assets, 0, 1)...)
+	changes = append(changes, diffList("radio", old.Radios, tree.Radios, 0, 1, 3, 4)...)
+	changes = append(changes, diffList("equipment", old.Equipment, tree.Equipment, 0, 1, 2, 3)...)
+	changes = append(changes, diffList("sensor", old.Sensors, tree.Sensors, 0, 1, 2, 3, 7)...)
+	changes = append(changes, diffList("datalogger", old.Dataloggers, tree.Dataloggers, 0, 1, 2, 3, 4)...)
+	changes = append(changes, diffList("firmware", old.Firmware, tree.Firmware, 0, 1, 4)...)
+
+	return changes
+}
+
+// diffValues compares keyed entities using their JSON representation.
+func diffValues(kind string, old, value map[string]interface{}) Changes {
+	var keys Keys
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range value {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Sort(keys)
+
+	var changes Changes
+	for _, k := range keys {
+		o, ook := old[k]
+		v, vok := value[k]
+		switch {
+		case !ook:
+			changes = append(changes, Change{Kind: kind, Key: k, Action: Added})
+		case !vok:
+			changes = append(changes, Change{Kind: kind, Key: k, Action: Removed})
+		default:
+			if fields := diffFields(o, v); len(fields) > 0 {
+				changes = append(changes, Change{Kind: kind, Key: k, Action: Changed, Fields: fields})
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffFields(old, value interface{}) []FieldChange {
+	om, vm := jsonFields(old), jsonFields(value)
+
+	var keys Keys
+	for k := range om {
+		keys = append(keys, k)
+	}
+	for k := range vm {
+		if _, ok := om[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Sort(keys)
+
+	var fields []FieldChange
+	for _, k := range keys {
+		if om[k] != vm[k] {
+			fields = append(fields, FieldChange{Field: k, Old: om[k], New: vm[k]})
+		}
+	}
+
+	return fields
+}
+
+func jsonFields(v interface{}) map[string]string {
+	fields := make(map[string]string)
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fields
+	}
+	for k, v := range m {
+		if s := string(v); s != "null" {
+			fields[k] = s
+		}
+	}
+
+	return fields
+}
+
+func diffLocations(old, tree *Tree) Changes {
+	index := func(t *Tree) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, l := range t.Locations {
+			m[l.Id] = l
+		}
+		return m
+	}
+	return diffValues("location", index(old), index(tree))
+}
+
+func diffNetworks(old, tree *Tree) Changes {
+	index := func(t *Tree) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, n := range t.Networks {
+			// devices are compared separately
+			n.Devices = nil
+			m[n.Location] = n
+		}
+		return m
+	}
+	return diffValues("network", index(old), index(tree))
+}
+
+func diffDevices(old, tree *Tree) Changes {
+	index := func(t *Tree) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, n := range t.Networks {
+			for _, d := range n.Devices {
+				m[d.Name] = LocatedDevice{Location: n.Location, Device: d}
+			}
+		}
+		return m
+	}
+	return diffValues("device", index(old), index(tree))
+}
+
+// diffAddresses compares address assignments keyed by device name and address, so that address changes
+// can be matched by the device name.
+func diffAddresses(old, tree *Tree) Changes {
+	index := func(t *Tree) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, n := range t.Networks {
+			for _, d := range n.Devices {
+				if d.Address != nil {
+					m[d.Name+", "+d.Address.IP.String()] = map[string]string{"device": d.Name, "address": d.Address.String()}
+				}
+				for _, a := range d.Aliases {
+					m[d.Name+", "+a.IP.String()] = map[string]string{"device": d.Name, "address": a.String(), "alias": "true"}
+				}
+			}
+		}
+		return m
+	}
+	return diffValues("address", index(old), index(tree))
+}
+
+func diffServices(old, tree *Tree) Changes {
+	index := func(t *Tree) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, p := range t.Providers {
+			for _, s := range p.Services {
+				m[s.Name] = ProviderService{Provider: p.Name, Service: s}
+			}
+		}
+		return m
+	}
+	return diffValues("service", index(old), index(tree))
+}
+
+func diffFrequencies(old, tree *Tree) Changes {
+	index := func(t *Tree) map[string]interface{} {
+		m := make(map[string]interface{})
+		for _, p := range t.Frequencies {
+			for _, f := range p.Frequencies {
+				m[f.Key] = f
+			}
+		}
+		return m
+	}
+	return diffValues("frequency", index(old), index(tree))
+}
+
+// diffList compares list rows keyed by the given column offsets.
+func diffList(kind string, old, list List, keys ...int) Changes {
+	index := func(l List) map[string]interface{} {
+		m := make(map[string]interface{})
+
+		data, err := Encode(l)
+		if err != nil || len(data) < 2 {
+			return m
+		}
+		for _, row := range data[1:] {
+			var k []string
+			for _, i := range keys {
+				k = append(k, row[i])
+			}
+			v := make(map[string]string)
+			for i, h := range data[0] {
+				v[h] = row[i]
+			}
+			m[strings.Join(k, ", ")] = v
+		}
+		return m
+	}
+	return diffValues(kind, index(old), index(list))
+}
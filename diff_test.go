@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffTrees(t *testing.T) {
+
+	old, err := LoadTree("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := LoadTree("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check identical tree differences.")
+	{
+		if changes := DiffTrees(old, tree); len(changes) != 0 {
+			t.Errorf("unexpected tree differences: [\n%s\n]", changes)
+		}
+	}
+
+	tree.Locations[0].Name = "A New Location Name"
+	tree.Networks[0].Devices = tree.Networks[0].Devices[1:]
+	tree.Networks[0].Devices[0].Address = MustParseIPAddress("192.168.192.6/28")
+	tree.Providers[0].Services = append(tree.Providers[0].Services, Service{Name: "New Service"})
+	tree.Equipment[0].Stop = MustParseTime("2011-06-01T00:00:00Z")
+
+	t.Log("Check tree differences.")
+	{
+		expected := `~ location location
+    name: "A Location Name" -> "A New Location Name"
+- device rf2somewhere-network
+~ device test1-network
+    address: "192.168.192.1/28" -> "192.168.192.6/28"
+- address rf2somewhere-network, 192.168.192.5
+- address test1-network, 192.168.192.1
++ address test1-network, 192.168.192.6
++ service New Service
+~ equipment Somewhere, Model #1, Serial #1, 2010-01-01T00:00:00Z
+    Installation Stop: "2011-01-01T00:00:00Z" -> "2011-06-01T00:00:00Z"`
+
+		changes := DiffTrees(old, tree)
+		if changes.String() != expected {
+			t.Errorf("tree differences mismatch: [\n%s\n]", SimpleDiff(changes.String(), expected))
+		}
+
+		b, err := changes.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Changes
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.String() != expected {
+			t.Errorf("tree differences json mismatch: [\n%s\n]", SimpleDiff(decoded.String(), expected))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"time"
+)
+
+const fieldSheetTemplate = `SITE ACCESS: {{.Id}} - {{.Name}}
+================================================================================
+
+Position:      {{if and .Latitude .Longitude}}{{LatLon .Latitude}} {{LatLon .Longitude}}{{with .Datum}} ({{.}}){{end}}{{else}}unknown{{end}}
+{{- with .Grid}}
+NZTM:          {{printf "%.0f" .Easting}} E {{printf "%.0f" .Northing}} N{{end}}
+{{- with .Elevation}}
+Elevation:     {{Float .}} m{{end}}
+{{- with .SiteAccess}}
+
+Gate:          {{Default "-" .Gate}}
+Keys:          {{if .Keys}}{{Join .Keys ", "}}{{else}}-{{end}}
+Landowner:     {{Default "-" .Landowner}}
+Contact:       {{Default "-" .Contact}}{{end}}
+
+Transport:     {{if .Transport}}{{Join .Transport ", "}} required{{else}}road{{end}}
+{{- with .SiteAccess}}{{if .Hazards}}
+
+Hazards:{{range .Hazards}}
+  [ ] {{.}}{{end}}{{end}}{{if .Restrictions}}
+
+Restrictions:{{range .Restrictions}}
+  - {{.}}{{end}}{{end}}{{end}}
+{{- if .Access}}
+
+Access notes:{{range Lines .Access}}
+  {{.}}{{end}}{{end}}
+{{- if .Links}}
+
+Linked sites:{{range .Links}}
+  - {{.Id}}{{with .Role}} ({{.}}){{end}}{{end}}{{end}}
+
+--------------------------------------------------------------------------------
+Printed {{.Printed}}
+`
+
+type fieldSheet struct {
+	Location
+	Grid      *GridPoint
+	Transport []string
+	Printed   string
+}
+
+// FieldSheet renders a printable plain text access sheet for the location, the time is shown as the
+// date printed so that out of date sheets can be recognised.
+func (loc Location) FieldSheet(at time.Time) (string, error) {
+	sheet := fieldSheet{
+		Location: loc,
+		Printed:  at.Format("2006-01-02"),
+	}
+	if g, ok := loc.NZTM(); ok {
+		sheet.Grid = &g
+	}
+	if a := loc.SiteAccess; a != nil {
+		if a.FourWheelDrive != nil && *a.FourWheelDrive {
+			sheet.Transport = append(sheet.Transport, "four wheel drive")
+		}
+		if a.Helicopter != nil && *a.Helicopter {
+			sheet.Transport = append(sheet.Transport, "helicopter")
+		}
+	}
+
+	return Render(FieldSheetTemplate, sheet)
+}
@@ -0,0 +1,38 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestLocation_FieldSheet(t *testing.T) {
+
+	at := MustParseTime("2016-01-02T00:00:00Z")
+
+	t.Log("Check rendering a location field sheet.")
+	{
+		b, err := ioutil.ReadFile("testdata/fieldsheet.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := testLocation.FieldSheet(at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != string(b) {
+			t.Errorf("field sheet mismatch: [\n%s\n]", SimpleDiff(s, string(b)))
+		}
+	}
+
+	t.Log("Check rendering a field sheet without access details.")
+	{
+		s, err := Location{Id: "bare", Name: "A Bare Location"}.FieldSheet(at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(s, "Position:      unknown") || strings.Contains(s, "Gate:") || strings.Contains(s, "Hazards:") {
+			t.Errorf("bare field sheet mismatch: [\n%s\n]", s)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package metadata
+
+import (
+	"sort"
+	"time"
+)
+
+func (f FirmwareInstalls) Len() int      { return len(f) }
+func (f FirmwareInstalls) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f FirmwareInstalls) Less(i, j int) bool {
+	switch {
+	case f[i].Model != f[j].Model:
+		return f[i].Model < f[j].Model
+	case f[i].Serial != f[j].Serial:
+		return f[i].Serial < f[j].Serial
+	default:
+		return f[i].Start.Before(f[j].Start)
+	}
+}
+
+// Firmware returns the firmware running on the given device at the given time.
+func (f FirmwareInstalls) Firmware(model, serial string, at time.Time) (*FirmwareInstall, bool) {
+	var current *FirmwareInstall
+	for i, v := range f {
+		if v.Model != model || v.Serial != serial || v.Start.After(at) {
+			continue
+		}
+		if current == nil || v.Start.After(current.Start) {
+			current = &f[i]
+		}
+	}
+	if current == nil {
+		return nil, false
+	}
+	return current, true
+}
+
+// Current returns the firmware running on each device at the given time, sorted by model and serial number.
+func (f FirmwareInstalls) Current(at time.Time) FirmwareInstalls {
+	latest := make(map[unit]FirmwareInstall)
+	for _, v := range f {
+		if v.Start.After(at) {
+			continue
+		}
+		u := unit{v.Model, v.Serial}
+		if l, ok := latest[u]; !ok || v.Start.After(l.Start) {
+			latest[u] = v
+		}
+	}
+
+	var current FirmwareInstalls
+	for _, v := range latest {
+		current = append(current, v)
+	}
+	sort.Sort(current)
+
+	return current
+}
+
+// Outdated returns the current firmware of each device whose model has a recommended firmware version
+// which the device is not running.
+func (f FirmwareInstalls) Outdated(catalog *ModelCatalog, at time.Time) FirmwareInstalls {
+	var outdated FirmwareInstalls
+	for _, v := range f.Current(at) {
+		m, err := catalog.Resolve(v.Model)
+		if err != nil || m.Recommended == nil {
+			continue
+		}
+		if v.Version != *m.Recommended {
+			outdated = append(outdated, v)
+		}
+	}
+	return outdated
+}
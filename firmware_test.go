@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+var testFirmwareInstalls FirmwareInstalls
+
+func init() {
+
+	testFirmwareInstalls = FirmwareInstalls{
+		FirmwareInstall{
+			Model:   "Model A",
+			Serial:  "Serial #1",
+			Version: "1.0.1",
+			Config:  "r1",
+			Start:   MustParseTime("2010-01-01T00:00:00Z"),
+		},
+		FirmwareInstall{
+			Model:   "Model A",
+			Serial:  "Serial #1",
+			Version: "1.2.0",
+			Config:  "r2",
+			Start:   MustParseTime("2012-01-01T00:00:00Z"),
+		},
+		FirmwareInstall{
+			Model:   "Model B",
+			Serial:  "Serial #2",
+			Version: "1.0.1",
+			Config:  "r1",
+			Start:   MustParseTime("2010-01-01T00:00:00Z"),
+		},
+		FirmwareInstall{
+			Model:   "Model B",
+			Serial:  "Serial #3",
+			Version: "1.2.0",
+			Start:   MustParseTime("2011-01-01T00:00:00Z"),
+		},
+	}
+}
+
+func TestFirmwareInstalls_ReadFile(t *testing.T) {
+	t.Log("Compare loaded firmware installs file.")
+	{
+		b, err := ioutil.ReadFile("testdata/firmware.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != Strings(testFirmwareInstalls) {
+			t.Errorf("firmware installs file text mismatch: [\n%s\n]", SimpleDiff(string(b), Strings(testFirmwareInstalls)))
+		}
+	}
+}
+
+func TestFirmwareInstalls_LoadFile(t *testing.T) {
+	t.Log("Check loading firmware installs file.")
+	{
+		var installs FirmwareInstalls
+		if err := LoadList("testdata/firmware.csv", &installs); err != nil {
+			t.Fatal(err)
+		}
+		if Strings(installs) != Strings(testFirmwareInstalls) {
+			t.Errorf("firmware installs file decode mismatch: [\n%s\n]", SimpleDiff(Strings(installs), Strings(testFirmwareInstalls)))
+		}
+	}
+}
+
+func TestFirmwareInstalls_LoadFiles(t *testing.T) {
+	t.Log("Check loading firmware installs files.")
+	{
+		var installs FirmwareInstalls
+		if err := LoadLists("testdata", "firmware.csv", &installs); err != nil {
+			t.Fatal(err)
+		}
+		if Strings(installs) != Strings(testFirmwareInstalls) {
+			t.Errorf("firmware installs file decode mismatch: [\n%s\n]", SimpleDiff(Strings(installs), Strings(testFirmwareInstalls)))
+		}
+	}
+}
+
+func TestFirmwareInstalls_Current(t *testing.T) {
+	t.Log("Check current device firmware.")
+	{
+		f, ok := testFirmwareInstalls.Firmware("Model A", "Serial #1", MustParseTime("2011-06-01T00:00:00Z"))
+		if !ok || f.Version != "1.0.1" || f.Config != "r1" {
+			t.Errorf("firmware mismatch: %v", f)
+		}
+		if _, ok := testFirmwareInstalls.Firmware("Model B", "Serial #3", MustParseTime("2010-06-01T00:00:00Z")); ok {
+			t.Error("firmware should not be found before its effective time")
+		}
+		if c := testFirmwareInstalls.Current(MustParseTime("2013-01-01T00:00:00Z")); len(c) != 3 || c[0].Version != "1.2.0" {
+			t.Errorf("current firmware mismatch: [\n%s\n]", Strings(c))
+		}
+	}
+
+	t.Log("Check outdated device firmware.")
+	{
+		catalog, err := NewModelCatalog([]Model{testModel})
+		if err != nil {
+			t.Fatal(err)
+		}
+		outdated := testFirmwareInstalls.Outdated(catalog, MustParseTime("2013-01-01T00:00:00Z"))
+		if len(outdated) != 1 || outdated[0].Serial != "Serial #2" {
+			t.Errorf("outdated firmware mismatch: [\n%s\n]", Strings(outdated))
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type rows [][]string
+
+func (r rows) Len() int      { return len(r) }
+func (r rows) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r rows) Less(i, j int) bool {
+	for k := 0; k < len(r[i]) && k < len(r[j]); k++ {
+		if r[i][k] != r[j][k] {
+			return r[i][k] < r[j][k]
+		}
+	}
+	return len(r[i]) < len(r[j])
+}
+
+// Canonical returns the canonical text of a metadata file, TOML files are rendered via their templates,
+// JSON and YAML files are re-encoded, and CSV files are encoded with their rows sorted.
+func Canonical(path string) ([]byte, error) {
+	v, err := loadMetadataFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case *Location:
+		return EncodeFormat(*v, fileFormat(path))
+	case *Network:
+		return EncodeFormat(*v, fileFormat(path))
+	case *Provider:
+		return EncodeFormat(*v, fileFormat(path))
+	case *Model:
+		return EncodeFormat(*v, fileFormat(path))
+	case *FrequencyPlan:
+		return EncodeFormat(*v, fileFormat(path))
+	case List:
+		data, err := Encode(indirectList(v))
+		if err != nil {
+			return nil, err
+		}
+		// an empty list has no header to encode
+		if len(data) == 0 {
+			return ioutil.ReadFile(path)
+		}
+		sort.Sort(rows(data[1:]))
+
+		var b bytes.Buffer
+		if err := csv.NewWriter(&b).WriteAll(data); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unable to format: %s", path)
+	}
+}
+
+func indirectList(list List) List {
+	switch l := list.(type) {
+	case *AssetList:
+		return *l
+	case *RadioInstalls:
+		return *l
+	case *EquipmentInstalls:
+		return *l
+	case *SensorInstalls:
+		return *l
+	case *DataloggerInstalls:
+		return *l
+	case *FirmwareInstalls:
+		return *l
+	default:
+		return list
+	}
+}
+
+// Format checks that each metadata file found below the given directory is in its canonical form,
+// returning the paths of the files which are not. If write is set these files are rewritten.
+func Format(dirname string, write bool) ([]string, error) {
+	var changed []string
+
+	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || !isMetadataFile(filepath.Base(path)) {
+			return nil
+		}
+
+		canonical, err := Canonical(path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		current, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(current, canonical) {
+			return nil
+		}
+		changed = append(changed, path)
+
+		if !write {
+			return nil
+		}
+		return ioutil.WriteFile(path, canonical, fi.Mode())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+
+	t.Log("Check formatting metadata files.")
+	{
+		changed, err := Format("testdata", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != 1 || changed[0] != filepath.Join("testdata", EquipmentFile) {
+			t.Errorf("format check mismatch: %v", changed)
+		}
+	}
+
+	t.Log("Check rewriting metadata files.")
+	{
+		dir, err := ioutil.TempDir("", "metadata")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, EquipmentFile)
+		if err := ioutil.WriteFile(path, []byte(Strings(testEquipmentInstalls)), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		changed, err := Format(dir, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != 1 {
+			t.Fatalf("format write mismatch: %v", changed)
+		}
+		if changed, err := Format(dir, false); err != nil || len(changed) != 0 {
+			t.Errorf("format write should be canonical: %v %v", changed, err)
+		}
+
+		var installs EquipmentInstalls
+		if err := LoadList(path, &installs); err != nil {
+			t.Fatal(err)
+		}
+		if len(installs) != len(testEquipmentInstalls) || installs[2].Model != "Model #1" || installs[2].Location != "Somewhere Else" {
+			t.Errorf("format write order mismatch: [\n%s\n]", Strings(installs))
+		}
+	}
+}
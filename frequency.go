@@ -0,0 +1,340 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const frequencyTemplate = `# Licensed radio frequency plan.
+
+## Frequency plan notes and documentation.
+{{if .Notes}}notes = """\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{Escape $v}}\n\
+{{end}}    """{{else}}#notes = """\
+#    \n\
+#    """{{end}}
+
+## The licensed frequencies, referenced by link and radio frequency keys.
+
+#[[frequency]]
+#    ## The frequency key.
+#    key = ""
+#
+#    ## Licensed centre frequency in MHz.
+#    centre = MHz
+#
+#    ## Licensed channel bandwidth in MHz.
+#    #bandwidth = MHz
+#
+#    ## Licence reference.
+#    #licence = ""
+#
+#    ## Frequency specific notes.
+#    #notes = """\
+#    #    \n\
+#    #    """{{range .Frequencies}}
+
+[[frequency]]
+    ## The frequency key.
+    key = "{{Escape .Key}}"
+
+    ## Licensed centre frequency in MHz.
+{{if .Centre}}    centre = {{Float .Centre}}{{else}}    #centre = MHz{{end}}
+
+    ## Licensed channel bandwidth in MHz.
+{{if .Bandwidth}}    bandwidth = {{Float .Bandwidth}}{{else}}    #bandwidth = MHz{{end}}
+
+    ## Licence reference.
+{{if .Licence}}    licence = "{{Escape .Licence}}"{{else}}    #licence = ""{{end}}
+
+    ## Frequency specific notes.
+{{if .Notes}}    notes = """\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{Escape $v}}\n\
+{{end}}        """{{else}}    #notes = """\
+    #    \n\
+    #    """{{end}}{{end}}
+
+# vim: tabstop=4 expandtab shiftwidth=4 softtabstop=4
+`
+
+// Frequency describes the licensed channel referred to by a frequency key.
+type Frequency struct {
+	Key       string   `json:"key"`
+	Centre    *float64 `json:"centre"`
+	Bandwidth *float64 `json:"bandwidth,omitempty"`
+	Licence   *string  `json:"licence,omitempty"`
+	Notes     *string  `json:"notes,omitempty"`
+}
+
+// FrequencyPlan is a set of frequency key definitions.
+type FrequencyPlan struct {
+	Notes       *string     `json:"notes,omitempty"`
+	Frequencies []Frequency `json:"frequencies,omitempty" toml:"frequency"`
+}
+
+func LoadFrequencyPlan(filename string) (*FrequencyPlan, error) {
+	var f FrequencyPlan
+
+	if err := DecodeFile(filename, &f); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+func LoadFrequencyPlans(dirname, filename string) ([]FrequencyPlan, error) {
+	var ff []FrequencyPlan
+
+	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && metadataName(filepath.Base(path)) == filename {
+			f, e := LoadFrequencyPlan(path)
+			if e != nil {
+				return e
+			}
+			ff = append(ff, *f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ff, nil
+}
+
+func (plan FrequencyPlan) StoreFrequencyPlan(path string) error {
+	return StoreFile(path, plan)
+}
+
+func (plan FrequencyPlan) String() string {
+	return mustExecute(FrequencyTemplate, plan)
+}
+
+// FrequencyKeys indexes frequency definitions by their keys.
+type FrequencyKeys map[string]Frequency
+
+// NewFrequencyKeys builds an index of frequency plans, keys are expected to be unique and to have a centre frequency.
+func NewFrequencyKeys(plans []FrequencyPlan) (FrequencyKeys, error) {
+	keys := make(FrequencyKeys)
+	for _, p := range plans {
+		for _, f := range p.Frequencies {
+			if _, ok := keys[f.Key]; ok {
+				return nil, fmt.Errorf("duplicate frequency key: %s", f.Key)
+			}
+			if f.Centre == nil {
+				return nil, fmt.Errorf("frequency key without a centre frequency: %s", f.Key)
+			}
+			keys[f.Key] = f
+		}
+	}
+	return keys, nil
+}
+
+// RadioKey returns the frequency key text of a radio install.
+func RadioKey(radio RadioInstall) string {
+	return strconv.FormatFloat(radio.Frequency, 'f', -1, 64)
+}
+
+// Lookup returns the frequency definition of a key.
+func (k FrequencyKeys) Lookup(key string) (Frequency, bool) {
+	f, ok := k[key]
+	return f, ok
+}
+
+// LinkFrequency returns the centre frequency of a link's key, it can be used when calculating link budgets.
+func (k FrequencyKeys) LinkFrequency(location string, link Link) (float64, bool) {
+	if link.Key == nil {
+		return 0.0, false
+	}
+	f, ok := k[*link.Key]
+	if !ok || f.Centre == nil {
+		return 0.0, false
+	}
+	return *f.Centre, true
+}
+
+// FrequencyReuse describes two radio paths which share a frequency key and polarity, and which have
+// endpoints within the checked distance of each other.
+type FrequencyReuse struct {
+	Key      string    `json:"key"`
+	Polarity string    `json:"polarity"`
+	Path     [2]string `json:"path"`
+	Other    [2]string `json:"other"`
+	Distance float64   `json:"distance"`
+}
+
+func (r FrequencyReuse) String() string {
+	polarity := r.Polarity
+	if polarity == "" {
+		polarity = "unknown polarity"
+	}
+	return fmt.Sprintf("frequency key %s (%s) used by %s-%s and %s-%s, %.1f km apart",
+		r.Key, polarity, r.Path[0], r.Path[1], r.Other[0], r.Other[1], r.Distance)
+}
+
+type FrequencyReuses []FrequencyReuse
+
+func (f FrequencyReuses) Len() int      { return len(f) }
+func (f FrequencyReuses) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f FrequencyReuses) Less(i, j int) bool {
+	switch {
+	case f[i].Key != f[j].Key:
+		return f[i].Key < f[j].Key
+	case f[i].Path != f[j].Path:
+		return f[i].Path[0]+"\x00"+f[i].Path[1] < f[j].Path[0]+"\x00"+f[j].Path[1]
+	default:
+		return f[i].Other[0]+"\x00"+f[i].Other[1] < f[j].Other[0]+"\x00"+f[j].Other[1]
+	}
+}
+
+// CheckFrequencyReuse finds radio paths with the same frequency key and polarity which have an endpoint
+// within the given distance, in kilometres, of an endpoint of another. The reciprocal links at either
+// end of a path are treated as the same path, and links without a polarity are assumed to clash with any.
+func CheckFrequencyReuse(locations []Location, radius float64) FrequencyReuses {
+	points := make(map[string]Point)
+	for _, l := range locations {
+		if p, ok := l.Point(); ok {
+			points[l.Id] = p
+		}
+	}
+
+	// gather the polarities used by each distinct path, for each key
+	paths := make(map[string]map[[2]string]map[string]bool)
+	for _, l := range locations {
+		for _, k := range l.Links {
+			if k.Key == nil || *k.Key == "" {
+				continue
+			}
+			ends := [2]string{l.Id, k.Id}
+			if ends[1] < ends[0] {
+				ends[0], ends[1] = ends[1], ends[0]
+			}
+			if _, ok := paths[*k.Key]; !ok {
+				paths[*k.Key] = make(map[[2]string]map[string]bool)
+			}
+			if _, ok := paths[*k.Key][ends]; !ok {
+				paths[*k.Key][ends] = make(map[string]bool)
+			}
+			paths[*k.Key][ends][Default("", k.Polarity)] = true
+		}
+	}
+
+	// a polarity shared by two paths, preferring known polarities, an unknown polarity matches any other
+	clash := func(a, b map[string]bool) (string, bool) {
+		var shared []string
+		for p := range a {
+			for q := range b {
+				switch {
+				case p == q:
+					shared = append(shared, p)
+				case p == "":
+					shared = append(shared, q)
+				case q == "":
+					shared = append(shared, p)
+				}
+			}
+		}
+		if len(shared) == 0 {
+			return "", false
+		}
+		sort.Strings(shared)
+		return shared[len(shared)-1], true
+	}
+
+	// the locations within range of each location, including itself, found using a spatial index
+	index := NewSpatialIndex(locations)
+	near := make(map[string][]string)
+	neighbours := func(id string) []string {
+		if n, ok := near[id]; ok {
+			return n
+		}
+		n := []string{id}
+		if p, ok := points[id]; ok {
+			for _, l := range index.Within(p, radius) {
+				if l.Id != id {
+					n = append(n, l.Id)
+				}
+			}
+		}
+		near[id] = n
+		return n
+	}
+
+	var reuses FrequencyReuses
+	for key, set := range paths {
+		var ends [][2]string
+		for e := range set {
+			ends = append(ends, e)
+		}
+		sort.Slice(ends, func(i, j int) bool {
+			return ends[i][0]+"\x00"+ends[i][1] < ends[j][0]+"\x00"+ends[j][1]
+		})
+
+		// the paths using each location
+		using := make(map[string][]int)
+		for i, e := range ends {
+			using[e[0]] = append(using[e[0]], i)
+			using[e[1]] = append(using[e[1]], i)
+		}
+
+		for i := 0; i < len(ends); i++ {
+			// only paths with an endpoint near an endpoint of this path need to be compared
+			var others []int
+			seen := make(map[int]bool)
+			for _, x := range ends[i] {
+				for _, y := range neighbours(x) {
+					for _, j := range using[y] {
+						if j > i && !seen[j] {
+							seen[j] = true
+							others = append(others, j)
+						}
+					}
+				}
+			}
+			sort.Ints(others)
+
+			for _, j := range others {
+				polarity, ok := clash(set[ends[i]], set[ends[j]])
+				if !ok {
+					continue
+				}
+
+				closest := -1.0
+				for _, x := range ends[i] {
+					for _, y := range ends[j] {
+						d := 0.0
+						if x != y {
+							px, ok := points[x]
+							py, ok2 := points[y]
+							if !ok || !ok2 {
+								continue
+							}
+							d = Distance(px, py)
+						}
+						if closest < 0.0 || d < closest {
+							closest = d
+						}
+					}
+				}
+				if closest < 0.0 || closest > radius {
+					continue
+				}
+
+				reuses = append(reuses, FrequencyReuse{
+					Key:      key,
+					Polarity: polarity,
+					Path:     ends[i],
+					Other:    ends[j],
+					Distance: closest,
+				})
+			}
+		}
+	}
+
+	sort.Sort(reuses)
+
+	return reuses
+}
@@ -0,0 +1,138 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+var testFrequencyPlan FrequencyPlan
+
+func init() {
+
+	testFrequencyPlan = FrequencyPlan{
+		Notes: &[]string{"Some Notes\nSome More Notes"}[0],
+		Frequencies: []Frequency{
+			Frequency{
+				Key:       "10",
+				Centre:    &[]float64{406.1}[0],
+				Bandwidth: &[]float64{0.0125}[0],
+				Licence:   &[]string{"Licence #1"}[0],
+			},
+			Frequency{
+				Key:    "Key 1",
+				Centre: &[]float64{5800}[0],
+				Notes:  &[]string{"Some Notes"}[0],
+			},
+		},
+	}
+}
+
+func TestFrequencyPlan_DecodeFile(t *testing.T) {
+	t.Log("Check decoding frequency plan file.")
+	{
+		plan, err := LoadFrequencyPlan("testdata/frequencies.toml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.String() != testFrequencyPlan.String() {
+			t.Errorf("frequency plan file text mismatch: [\n%s\n]", SimpleDiff(plan.String(), testFrequencyPlan.String()))
+		}
+	}
+}
+
+func TestFrequencyPlan_EncodeFile(t *testing.T) {
+	t.Log("Check encoding frequency plan file.")
+	{
+		b, err := ioutil.ReadFile("testdata/frequencies.toml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != testFrequencyPlan.String() {
+			t.Errorf("frequency plan file text mismatch: [\n%s\n]", SimpleDiff(string(b), testFrequencyPlan.String()))
+		}
+	}
+}
+
+func TestFrequencyPlan_Keys(t *testing.T) {
+
+	t.Log("Check frequency key lookups.")
+	{
+		keys, err := NewFrequencyKeys([]FrequencyPlan{testFrequencyPlan})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f, ok := keys.Lookup(RadioKey(RadioInstall{Frequency: 10})); !ok || *f.Centre != 406.1 {
+			t.Error("radio frequency key lookup mismatch")
+		}
+		if f, ok := keys.LinkFrequency("A", Link{Id: "B", Key: &[]string{"Key 1"}[0]}); !ok || f != 5800 {
+			t.Error("link frequency lookup mismatch")
+		}
+		if _, ok := keys.LinkFrequency("A", Link{Id: "B", Key: &[]string{"Key 2"}[0]}); ok {
+			t.Error("unexpected link frequency")
+		}
+	}
+
+	t.Log("Check duplicate frequency keys.")
+	{
+		if _, err := NewFrequencyKeys([]FrequencyPlan{testFrequencyPlan, testFrequencyPlan}); err == nil {
+			t.Error("expected duplicate frequency key error")
+		}
+	}
+}
+
+func TestFrequencyPlan_CheckFrequencyReuse(t *testing.T) {
+
+	key := func(s string) *string { return &s }
+
+	// two parallel paths a few kilometres apart, and a distant path, all on the same key
+	a := testGeoLocation("A", -41.00, 175.00)
+	b := testGeoLocation("B", -41.00, 175.20)
+	c := testGeoLocation("C", -41.03, 175.00)
+	d := testGeoLocation("D", -41.03, 175.20)
+	e := testGeoLocation("E", -45.00, 170.00)
+	f := testGeoLocation("F", -45.00, 170.20)
+
+	a.Links = []Link{{Id: "B", Key: key("K1"), Polarity: key("V")}, {Id: "E", Key: key("K2"), Polarity: key("H")}}
+	b.Links = []Link{{Id: "A", Key: key("K1"), Polarity: key("V")}}
+	c.Links = []Link{{Id: "D", Key: key("K1"), Polarity: key("V")}}
+	d.Links = []Link{{Id: "C", Key: key("K1"), Polarity: key("H")}}
+	e.Links = []Link{{Id: "F", Key: key("K1")}}
+	f.Links = []Link{{Id: "A", Key: key("K2"), Polarity: key("V")}}
+
+	locations := []Location{a, b, c, d, e, f}
+
+	t.Log("Check nearby frequency reuse.")
+	{
+		reuses := CheckFrequencyReuse(locations, 10.0)
+		if len(reuses) != 1 {
+			t.Fatalf("frequency reuse mismatch: %v", reuses)
+		}
+		if s := reuses[0].String(); s != "frequency key K1 (V) used by A-B and C-D, 3.3 km apart" {
+			t.Errorf("frequency reuse mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check distant frequency reuse.")
+	{
+		reuses := CheckFrequencyReuse(locations, 1000.0)
+		if len(reuses) != 3 {
+			t.Errorf("frequency reuse mismatch: %v", reuses)
+		}
+	}
+
+	t.Log("Check frequency reuse across the antimeridian.")
+	{
+		g := testGeoLocation("G", -44.00, 179.99)
+		h := testGeoLocation("H", -44.10, 179.99)
+		i := testGeoLocation("I", -44.00, -179.99)
+		j := testGeoLocation("J", -44.10, -179.99)
+
+		g.Links = []Link{{Id: "H", Key: key("K3"), Polarity: key("V")}}
+		i.Links = []Link{{Id: "J", Key: key("K3"), Polarity: key("V")}}
+
+		reuses := CheckFrequencyReuse([]Location{g, h, i, j}, 5.0)
+		if len(reuses) != 1 || reuses[0].Path != [2]string{"G", "H"} || reuses[0].Other != [2]string{"I", "J"} {
+			t.Errorf("antimeridian frequency reuse mismatch: %v", reuses)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package metadata
+
+import (
+	"math"
+	"sort"
+)
+
+// EarthRadius is the mean radius of the earth, in kilometres, used for great circle calculations.
+const EarthRadius = 6371.0
+
+// Point is a geographic position in decimal degrees.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Point returns the position of the location, if it has one.
+func (l Location) Point() (Point, bool) {
+	if l.Latitude == nil || l.Longitude == nil {
+		return Point{}, false
+	}
+	return Point{Latitude: *l.Latitude, Longitude: *l.Longitude}, true
+}
+
+func radians(d float64) float64 { return d * math.Pi / 180.0 }
+func degrees(r float64) float64 { return r * 180.0 / math.Pi }
+
+// Distance returns the great circle distance between two points, in kilometres.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dlat, dlon := lat2-lat1, radians(b.Longitude-a.Longitude)
+
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlon/2)*math.Sin(dlon/2)
+
+	return 2.0 * EarthRadius * math.Asin(math.Min(1.0, math.Sqrt(h)))
+}
+
+// Bearing returns the initial great circle bearing from one point to another, in degrees clockwise from north.
+func Bearing(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dlon := radians(b.Longitude - a.Longitude)
+
+	y := math.Sin(dlon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dlon)
+
+	return math.Mod(degrees(math.Atan2(y, x))+360.0, 360.0)
+}
+
+// Inside checks whether a point lies within a polygon, given as a list of vertices. The polygon edges are
+// treated as straight lines in latitude and longitude and should not cross the antimeridian.
+func Inside(p Point, polygon []Point) bool {
+	var inside bool
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Latitude > p.Latitude) != (b.Latitude > p.Latitude) &&
+			p.Longitude < (b.Longitude-a.Longitude)*(p.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// spatialCell is the size, in degrees, of each spatial index grid cell.
+const spatialCell = 1.0
+
+type cell struct {
+	row, col int
+}
+
+// SpatialIndex is a simple grid based index for finding locations by position, locations without
+// a latitude and longitude are not indexed.
+type SpatialIndex struct {
+	locations []Location
+	points    []Point
+	cells     map[cell][]int
+}
+
+// NewSpatialIndex builds an index of the given locations.
+func NewSpatialIndex(locations []Location) *SpatialIndex {
+	s := SpatialIndex{
+		cells: make(map[cell][]int),
+	}
+	for _, l := range locations {
+		p, ok := l.Point()
+		if !ok {
+			continue
+		}
+		c := s.cell(p)
+		s.cells[c] = append(s.cells[c], len(s.locations))
+		s.locations = append(s.locations, l)
+		s.points = append(s.points, p)
+	}
+	return &s
+}
+
+func (s *SpatialIndex) rows() int { return int(math.Ceil(180.0 / spatialCell)) }
+func (s *SpatialIndex) cols() int { return int(math.Ceil(360.0 / spatialCell)) }
+
+func (s *SpatialIndex) row(lat float64) int {
+	r := int(math.Floor((lat + 90.0) / spatialCell))
+	switch {
+	case r < 0:
+		return 0
+	case r >= s.rows():
+		return s.rows() - 1
+	default:
+		return r
+	}
+}
+
+func (s *SpatialIndex) col(lon float64) int {
+	c := int(math.Floor((lon + 180.0) / spatialCell))
+	return ((c % s.cols()) + s.cols()) % s.cols()
+}
+
+func (s *SpatialIndex) cell(p Point) cell {
+	return cell{row: s.row(p.Latitude), col: s.col(p.Longitude)}
+}
+
+// search returns the indices of locations in the grid cells covering the given area, the west longitude
+// may be greater than the east longitude for areas crossing the antimeridian. An east longitude of 180
+// wraps into the first column, so such areas also run through to the last column.
+func (s *SpatialIndex) search(south, west, north, east float64, all bool) []int {
+	var cols []int
+	switch w, e := s.col(west), s.col(east); {
+	case all || east-west >= 360.0:
+		for c := 0; c < s.cols(); c++ {
+			cols = append(cols, c)
+		}
+	case w <= e && west <= east && east < 180.0:
+		for c := w; c <= e; c++ {
+			cols = append(cols, c)
+		}
+	default:
+		for c := w; c < s.cols(); c++ {
+			cols = append(cols, c)
+		}
+		for c := 0; c <= e && c < w; c++ {
+			cols = append(cols, c)
+		}
+	}
+
+	var found []int
+	for r := s.row(south); r <= s.row(north); r++ {
+		for _, c := range cols {
+			found = append(found, s.cells[cell{row: r, col: c}]...)
+		}
+	}
+	return found
+}
+
+func (s *SpatialIndex) byDistance(p Point, found []int) []Location {
+	sort.Slice(found, func(i, j int) bool {
+		di, dj := Distance(p, s.points[found[i]]), Distance(p, s.points[found[j]])
+		if di != dj {
+			return di < dj
+		}
+		return s.locations[found[i]].Id < s.locations[found[j]].Id
+	})
+
+	var locations []Location
+	for _, i := range found {
+		locations = append(locations, s.locations[i])
+	}
+	return locations
+}
+
+func (s *SpatialIndex) byId(found []int) []Location {
+	var locations []Location
+	for _, i := range found {
+		locations = append(locations, s.locations[i])
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Id < locations[j].Id })
+	return locations
+}
+
+// Within returns the locations within the given distance, in kilometres, of a point, nearest first.
+func (s *SpatialIndex) Within(p Point, radius float64) []Location {
+	dlat := degrees(radius / EarthRadius)
+
+	// the widest longitude span of the search circle
+	var dlon float64
+	all := p.Latitude-dlat <= -90.0 || p.Latitude+dlat >= 90.0
+	if x := math.Sin(radius/EarthRadius) / math.Cos(radians(p.Latitude)); x < 1.0 && !all {
+		dlon = degrees(math.Asin(x))
+	} else {
+		all = true
+	}
+
+	var found []int
+	for _, i := range s.search(p.Latitude-dlat, p.Longitude-dlon, p.Latitude+dlat, p.Longitude+dlon, all) {
+		if Distance(p, s.points[i]) <= radius {
+			found = append(found, i)
+		}
+	}
+
+	return s.byDistance(p, found)
+}
+
+// Nearest returns up to n locations closest to a point, nearest first.
+func (s *SpatialIndex) Nearest(p Point, n int) []Location {
+	if n <= 0 {
+		return nil
+	}
+
+	// widen the search until enough locations have been found, or the whole earth has been covered
+	for radius := 10.0; ; radius *= 2.0 {
+		found := s.Within(p, radius)
+		if len(found) >= n {
+			return found[:n]
+		}
+		if radius > math.Pi*EarthRadius {
+			return found
+		}
+	}
+}
+
+// Box returns the locations within a bounding box, ordered by id. The west longitude may be greater than
+// the east longitude for boxes crossing the antimeridian.
+func (s *SpatialIndex) Box(south, west, north, east float64) []Location {
+	var found []int
+	for _, i := range s.search(south, west, north, east, false) {
+		p := s.points[i]
+		if p.Latitude < south || p.Latitude > north {
+			continue
+		}
+		if east-west < 360.0 && math.Mod(p.Longitude-west+720.0, 360.0) > math.Mod(east-west+720.0, 360.0) {
+			continue
+		}
+		found = append(found, i)
+	}
+
+	return s.byId(found)
+}
+
+// Polygon returns the locations inside a polygon, ordered by id.
+func (s *SpatialIndex) Polygon(polygon []Point) []Location {
+	if len(polygon) < 3 {
+		return nil
+	}
+
+	south, west, north, east := polygon[0].Latitude, polygon[0].Longitude, polygon[0].Latitude, polygon[0].Longitude
+	for _, v := range polygon[1:] {
+		south, north = math.Min(south, v.Latitude), math.Max(north, v.Latitude)
+		west, east = math.Min(west, v.Longitude), math.Max(east, v.Longitude)
+	}
+
+	var found []int
+	for _, i := range s.search(south, west, north, east, false) {
+		if Inside(s.points[i], polygon) {
+			found = append(found, i)
+		}
+	}
+
+	return s.byId(found)
+}
+
+// LocationDistance returns the great circle distance, in kilometres, and the initial bearing between
+// two locations, if both have positions.
+func LocationDistance(from, to Location) (float64, float64, bool) {
+	a, ok := from.Point()
+	if !ok {
+		return 0.0, 0.0, false
+	}
+	b, ok := to.Point()
+	if !ok {
+		return 0.0, 0.0, false
+	}
+	return Distance(a, b), Bearing(a, b), true
+}
@@ -0,0 +1,124 @@
+package metadata
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func testGeoLocation(id string, lat, lon float64) Location {
+	return Location{Id: id, Name: id, Latitude: &lat, Longitude: &lon}
+}
+
+var testGeoLocations = []Location{
+	testGeoLocation("WEL", -41.2865, 174.7762),
+	testGeoLocation("AKL", -36.8485, 174.7633),
+	testGeoLocation("CHC", -43.5321, 172.6362),
+	testGeoLocation("CHT", -43.9500, -176.5600),
+	testGeoLocation("NPE", -39.4902, 176.9120),
+	Location{Id: "XXX", Name: "Nowhere"},
+}
+
+func locationIds(locations []Location) string {
+	var ids []string
+	for _, l := range locations {
+		ids = append(ids, l.Id)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestGeo_Distance(t *testing.T) {
+
+	wel, _ := testGeoLocations[0].Point()
+	akl, _ := testGeoLocations[1].Point()
+	cht, _ := testGeoLocations[3].Point()
+
+	t.Log("Check great circle distances.")
+	{
+		if d := Distance(wel, akl); math.Abs(d-493.4) > 1.0 {
+			t.Errorf("distance mismatch: %g", d)
+		}
+		if d := Distance(wel, cht); math.Abs(d-767.8) > 1.0 {
+			t.Errorf("antimeridian distance mismatch: %g", d)
+		}
+		if d := Distance(wel, wel); d != 0.0 {
+			t.Errorf("zero distance mismatch: %g", d)
+		}
+	}
+
+	t.Log("Check great circle bearings.")
+	{
+		if b := Bearing(Point{0, 0}, Point{1, 0}); math.Abs(b) > 1e-9 {
+			t.Errorf("north bearing mismatch: %g", b)
+		}
+		if b := Bearing(Point{0, 0}, Point{0, 1}); math.Abs(b-90.0) > 1e-9 {
+			t.Errorf("east bearing mismatch: %g", b)
+		}
+		if b := Bearing(wel, cht); b < 90.0 || b > 120.0 {
+			t.Errorf("antimeridian bearing mismatch: %g", b)
+		}
+	}
+
+	t.Log("Check location distances.")
+	{
+		if _, _, ok := LocationDistance(testGeoLocations[0], testGeoLocations[5]); ok {
+			t.Error("expected no distance without a position")
+		}
+		if d, _, ok := LocationDistance(testGeoLocations[0], testGeoLocations[1]); !ok || math.Abs(d-493.4) > 1.0 {
+			t.Errorf("location distance mismatch: %g", d)
+		}
+	}
+}
+
+func TestGeo_SpatialIndex(t *testing.T) {
+
+	index := NewSpatialIndex(testGeoLocations)
+	wel, _ := testGeoLocations[0].Point()
+
+	t.Log("Check locations within a radius.")
+	{
+		if ids := locationIds(index.Within(wel, 400.0)); ids != "WEL,NPE,CHC" {
+			t.Errorf("within mismatch: %s", ids)
+		}
+		if ids := locationIds(index.Within(wel, 1000.0)); ids != "WEL,NPE,CHC,AKL,CHT" {
+			t.Errorf("within mismatch: %s", ids)
+		}
+	}
+
+	t.Log("Check nearest locations.")
+	{
+		if ids := locationIds(index.Nearest(wel, 2)); ids != "WEL,NPE" {
+			t.Errorf("nearest mismatch: %s", ids)
+		}
+		if ids := locationIds(index.Nearest(Point{51.5, 0.0}, 10)); ids != "AKL,NPE,WEL,CHC,CHT" {
+			t.Errorf("nearest mismatch: %s", ids)
+		}
+	}
+
+	t.Log("Check locations within a bounding box.")
+	{
+		if ids := locationIds(index.Box(-42.0, 170.0, -36.0, 178.0)); ids != "AKL,NPE,WEL" {
+			t.Errorf("box mismatch: %s", ids)
+		}
+		if ids := locationIds(index.Box(-45.0, 175.0, -40.0, -175.0)); ids != "CHT" {
+			t.Errorf("antimeridian box mismatch: %s", ids)
+		}
+		if ids := locationIds(index.Box(-90.0, -180.0, 90.0, 180.0)); ids != "AKL,CHC,CHT,NPE,WEL" {
+			t.Errorf("world box mismatch: %s", ids)
+		}
+		if ids := locationIds(index.Box(-90.0, -179.9, 90.0, 180.0)); ids != "AKL,CHC,CHT,NPE,WEL" {
+			t.Errorf("box ending at the antimeridian mismatch: %s", ids)
+		}
+		if ids := locationIds(index.Box(-50.0, 170.0, -30.0, 180.0)); ids != "AKL,CHC,NPE,WEL" {
+			t.Errorf("box ending at the antimeridian mismatch: %s", ids)
+		}
+	}
+
+	t.Log("Check locations within a polygon.")
+	{
+		polygon := []Point{{-42.0, 172.0}, {-38.0, 178.0}, {-44.0, 178.0}}
+		if ids := locationIds(index.Polygon(polygon)); ids != "NPE,WEL" {
+			t.Errorf("polygon mismatch: %s", ids)
+		}
+	}
+}
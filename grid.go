@@ -0,0 +1,165 @@
+package metadata
+
+import (
+	"fmt"
+	"math"
+)
+
+// GRS80 ellipsoid parameters, as used by NZGD2000 and, to well below a millimetre, by WGS84.
+const (
+	grs80A = 6378137.0
+	grs80F = 1.0 / 298.257222101
+)
+
+// GridPoint is a projected position in metres.
+type GridPoint struct {
+	Easting  float64 `json:"easting"`
+	Northing float64 `json:"northing"`
+}
+
+// TransverseMercator describes a transverse mercator projection with an origin latitude on the equator.
+type TransverseMercator struct {
+	Name            string
+	CentralMeridian float64
+	ScaleFactor     float64
+	FalseEasting    float64
+	FalseNorthing   float64
+}
+
+// NZTM is the New Zealand Transverse Mercator 2000 projection (EPSG:2193).
+var NZTM = TransverseMercator{
+	Name:            "NZTM2000",
+	CentralMeridian: 173.0,
+	ScaleFactor:     0.9996,
+	FalseEasting:    1600000.0,
+	FalseNorthing:   10000000.0,
+}
+
+// UTM returns the Universal Transverse Mercator projection for a zone and hemisphere.
+func UTM(zone int, south bool) TransverseMercator {
+	tm := TransverseMercator{
+		Name:            fmt.Sprintf("UTM%dN", zone),
+		CentralMeridian: float64(zone)*6.0 - 183.0,
+		ScaleFactor:     0.9996,
+		FalseEasting:    500000.0,
+	}
+	if south {
+		tm.Name, tm.FalseNorthing = fmt.Sprintf("UTM%dS", zone), 10000000.0
+	}
+	return tm
+}
+
+// UTMZone returns the standard zone number and hemisphere for a point, the Norwegian and Svalbard
+// exceptions are not applied.
+func UTMZone(p Point) (int, bool) {
+	zone := int(math.Floor((p.Longitude+180.0)/6.0))%60 + 1
+	if zone < 1 {
+		zone += 60
+	}
+	return zone, p.Latitude < 0.0
+}
+
+// krueger holds the series coefficients used for the projection, to fourth order in the third flattening.
+type krueger struct {
+	a, alpha, beta, delta [4]float64
+	e, r                  float64
+}
+
+var grs80 = func() krueger {
+	n := grs80F / (2.0 - grs80F)
+	n2, n3, n4 := n*n, n*n*n, n*n*n*n
+
+	return krueger{
+		r: grs80A / (1.0 + n) * (1.0 + n2/4.0 + n4/64.0),
+		e: math.Sqrt(grs80F * (2.0 - grs80F)),
+		alpha: [4]float64{
+			n/2.0 - 2.0/3.0*n2 + 5.0/16.0*n3 + 41.0/180.0*n4,
+			13.0/48.0*n2 - 3.0/5.0*n3 + 557.0/1440.0*n4,
+			61.0/240.0*n3 - 103.0/140.0*n4,
+			49561.0 / 161280.0 * n4,
+		},
+		beta: [4]float64{
+			n/2.0 - 2.0/3.0*n2 + 37.0/96.0*n3 - 1.0/360.0*n4,
+			1.0/48.0*n2 + 1.0/15.0*n3 - 437.0/1440.0*n4,
+			17.0/480.0*n3 - 37.0/840.0*n4,
+			4397.0 / 161280.0 * n4,
+		},
+		delta: [4]float64{
+			2.0*n - 2.0/3.0*n2 - 2.0*n3 + 116.0/45.0*n4,
+			7.0/3.0*n2 - 8.0/5.0*n3 - 227.0/45.0*n4,
+			56.0/15.0*n3 - 136.0/35.0*n4,
+			4279.0 / 630.0 * n4,
+		},
+	}
+}()
+
+// Forward projects a geographic point onto the grid.
+func (tm TransverseMercator) Forward(p Point) GridPoint {
+	phi := radians(p.Latitude)
+	lambda := radians(math.Remainder(p.Longitude-tm.CentralMeridian, 360.0))
+
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - grs80.e*math.Atanh(grs80.e*math.Sin(phi)))
+	xi0 := math.Atan2(t, math.Cos(lambda))
+	eta0 := math.Atanh(math.Sin(lambda) / math.Sqrt(1.0+t*t))
+
+	xi, eta := xi0, eta0
+	for j, a := range grs80.alpha {
+		k := 2.0 * float64(j+1)
+		xi += a * math.Sin(k*xi0) * math.Cosh(k*eta0)
+		eta += a * math.Cos(k*xi0) * math.Sinh(k*eta0)
+	}
+
+	return GridPoint{
+		Easting:  tm.FalseEasting + tm.ScaleFactor*grs80.r*eta,
+		Northing: tm.FalseNorthing + tm.ScaleFactor*grs80.r*xi,
+	}
+}
+
+// Inverse converts a grid position back into a geographic point.
+func (tm TransverseMercator) Inverse(g GridPoint) Point {
+	xi := (g.Northing - tm.FalseNorthing) / (tm.ScaleFactor * grs80.r)
+	eta := (g.Easting - tm.FalseEasting) / (tm.ScaleFactor * grs80.r)
+
+	xi0, eta0 := xi, eta
+	for j, b := range grs80.beta {
+		k := 2.0 * float64(j+1)
+		xi0 -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta0 -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	chi := math.Asin(math.Sin(xi0) / math.Cosh(eta0))
+	phi := chi
+	for j, d := range grs80.delta {
+		phi += d * math.Sin(2.0*float64(j+1)*chi)
+	}
+
+	return Point{
+		Latitude:  degrees(phi),
+		Longitude: math.Remainder(tm.CentralMeridian+degrees(math.Atan2(math.Sinh(eta0), math.Cos(xi0))), 360.0),
+	}
+}
+
+// Grid returns the location's position on the given projection, if it has one. The location's
+// datum is assumed to be compatible with the projection.
+func (l Location) Grid(tm TransverseMercator) (GridPoint, bool) {
+	p, ok := l.Point()
+	if !ok {
+		return GridPoint{}, false
+	}
+	return tm.Forward(p), true
+}
+
+// NZTM returns the location's New Zealand Transverse Mercator position, if it has one.
+func (l Location) NZTM() (GridPoint, bool) {
+	return l.Grid(NZTM)
+}
+
+// UTM returns the location's position in its own UTM zone, together with the projection used.
+func (l Location) UTM() (GridPoint, TransverseMercator, bool) {
+	p, ok := l.Point()
+	if !ok {
+		return GridPoint{}, TransverseMercator{}, false
+	}
+	tm := UTM(UTMZone(p))
+	return tm.Forward(p), tm, true
+}
@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGrid_TransverseMercator(t *testing.T) {
+
+	t.Log("Check projection origins.")
+	{
+		g := NZTM.Forward(Point{0.0, 173.0})
+		if math.Abs(g.Easting-1600000.0) > 1e-6 || math.Abs(g.Northing-10000000.0) > 1e-6 {
+			t.Errorf("nztm origin mismatch: %v", g)
+		}
+	}
+
+	t.Log("Check utm zones.")
+	{
+		if z, s := UTMZone(Point{-41.29, 174.78}); z != 60 || !s {
+			t.Errorf("utm zone mismatch: %d %v", z, s)
+		}
+		if z, s := UTMZone(Point{43.64, -79.39}); z != 17 || s {
+			t.Errorf("utm zone mismatch: %d %v", z, s)
+		}
+		if z, _ := UTMZone(Point{0.0, 180.0}); z != 1 {
+			t.Errorf("utm zone mismatch: %d", z)
+		}
+	}
+
+	t.Log("Check utm projection.")
+	{
+		// the CN Tower, Toronto
+		g := UTM(17, false).Forward(Point{43.642567, -79.387139})
+		if math.Abs(g.Easting-630084.0) > 1.0 || math.Abs(g.Northing-4833439.0) > 1.0 {
+			t.Errorf("utm projection mismatch: %v", g)
+		}
+	}
+
+	t.Log("Check projection round trips.")
+	{
+		for _, p := range []Point{{-41.2865, 174.7762}, {-34.4, 172.7}, {-47.3, 167.5}, {-43.95, -176.56}} {
+			q := NZTM.Inverse(NZTM.Forward(p))
+			if math.Abs(q.Latitude-p.Latitude) > 1e-9 || math.Abs(q.Longitude-p.Longitude) > 1e-9 {
+				t.Errorf("nztm round trip mismatch: %v != %v", q, p)
+			}
+			zone, south := UTMZone(p)
+			q = UTM(zone, south).Inverse(UTM(zone, south).Forward(p))
+			if math.Abs(q.Latitude-p.Latitude) > 1e-9 || math.Abs(q.Longitude-p.Longitude) > 1e-9 {
+				t.Errorf("utm round trip mismatch: %v != %v", q, p)
+			}
+		}
+	}
+
+	t.Log("Check location grid positions.")
+	{
+		if _, ok := (Location{}).NZTM(); ok {
+			t.Error("expected no grid position without coordinates")
+		}
+		if _, tm, ok := testLocation.UTM(); !ok || tm.Name != "UTM60S" {
+			t.Errorf("location utm mismatch: %s", tm.Name)
+		}
+	}
+}
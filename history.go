@@ -0,0 +1,185 @@
+package metadata
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HistoryEntry holds the changes to an entity found in a single commit.
+type HistoryEntry struct {
+	Commit  string    `json:"commit"`
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+	Changes Changes   `json:"changes"`
+}
+
+// Matches returns whether the change refers to the given entity key, either directly or as part of a list row key.
+func (c Change) Matches(key string) bool {
+	if c.Key == key {
+		return true
+	}
+	for _, k := range strings.Split(c.Key, ", ") {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// History walks the commits of the git repository found at the given path which touch metadata files,
+// oldest first, and returns a timeline of the semantic changes made to the entity with the given key, e.g.
+// a location id, a device name or a serial number. Revisions with files which can not be parsed are skipped,
+// their changes are found against the next revision which can be. Only a local git binary is required.
+func History(repo, key string) ([]HistoryEntry, error) {
+
+	tmp, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	args := []string{"log", "--reverse", "--format=%H%x09%aI%x09%an%x09%s", "--"}
+	commits, err := gitCommand(repo, append(args, metadataPathspecs()...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// parsed files, or their parse errors, are cached by their blob hash
+	blobs := make(map[string]interface{})
+
+	var history []HistoryEntry
+
+	old := &Tree{}
+	scanner := bufio.NewScanner(bytes.NewReader(commits))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "\t", 4)
+		if len(parts) != 4 {
+			continue
+		}
+
+		tree, ok, err := gitTree(repo, parts[0], tmp, blobs)
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %v", parts[0], err)
+		}
+		if !ok {
+			continue
+		}
+
+		var changes Changes
+		for _, c := range DiffTrees(old, tree) {
+			if c.Matches(key) {
+				changes = append(changes, c)
+			}
+		}
+		old = tree
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		when, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, HistoryEntry{
+			Commit:  parts[0],
+			Time:    when.UTC(),
+			Author:  parts[2],
+			Subject: parts[3],
+			Changes: changes,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// metadataPathspecs returns git pathspecs matching metadata files in any directory.
+func metadataPathspecs() []string {
+	var specs []string
+	for _, name := range []string{LocationFile, NetworkFile, ProviderFile, ModelFile, FrequencyFile} {
+		base := strings.TrimSuffix(name, TOMLFormat)
+		for _, ext := range []string{TOMLFormat, JSONFormat, YAMLFormat, ".yml"} {
+			specs = append(specs, ":(glob)**/"+base+ext)
+		}
+	}
+	for _, name := range []string{AssetFile, RadioFile, EquipmentFile, SensorFile, DataloggerFile, FirmwareFile} {
+		specs = append(specs, ":(glob)**/"+name)
+	}
+	return specs
+}
+
+func gitCommand(repo string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// gitTree loads the metadata tree at the given revision, files are extracted into the temporary directory as needed.
+// It returns false if any of the metadata files could not be parsed.
+func gitTree(repo, rev, tmp string, blobs map[string]interface{}) (*Tree, bool, error) {
+
+	list, err := gitCommand(repo, "ls-tree", "-r", "-z", rev)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var paths []string
+	files := make(map[string]watchedFile)
+
+	for _, line := range strings.Split(string(list), "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields := strings.Fields(parts[0])
+		if len(fields) != 3 || fields[1] != "blob" || !isMetadataFile(filepath.Base(parts[1])) {
+			continue
+		}
+
+		hash := fields[2]
+		if _, ok := blobs[hash]; !ok {
+			data, err := gitCommand(repo, "cat-file", "blob", hash)
+			if err != nil {
+				return nil, false, err
+			}
+			path := filepath.Join(tmp, filepath.Base(parts[1]))
+			if err := ioutil.WriteFile(path, data, 0644); err != nil {
+				return nil, false, err
+			}
+			v, err := loadMetadataFile(path)
+			if err != nil {
+				blobs[hash] = err
+			} else {
+				blobs[hash] = v
+			}
+		}
+		if _, ok := blobs[hash].(error); ok {
+			return nil, false, nil
+		}
+
+		paths = append(paths, parts[1])
+		files[parts[1]] = watchedFile{value: blobs[hash]}
+	}
+
+	return buildTree(paths, files), true, nil
+}
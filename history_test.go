@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Tester", "-c", "user.email=tester@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	commit := func(n Network, message string) {
+		if err := n.StoreNetwork(filepath.Join(dir, n.Location, NetworkFile)); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+	}
+
+	git("init", "-q")
+
+	network := Network{
+		Location: "abc",
+		Devices: []Device{
+			Device{Name: "test-abc", Model: "Test", Address: MustParseIPAddress("192.168.1.1/28")},
+			Device{Name: "other-abc", Model: "Test", Address: MustParseIPAddress("192.168.1.2/28")},
+		},
+	}
+	commit(network, "Add abc network")
+
+	network.Devices[1].Model = "Other"
+	commit(network, "Update other device")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "Add notes")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, network.Location, NetworkFile), []byte("[broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "Break abc network")
+
+	network.Devices[0].Address = MustParseIPAddress("192.168.1.3/28")
+	commit(network, "Move test device")
+
+	t.Log("Check device history.")
+	{
+		history, err := History(dir, "test-abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 2 {
+			t.Fatalf("history length mismatch: %d != 2", len(history))
+		}
+		if history[0].Subject != "Add abc network" || history[0].Changes[0].Action != Added {
+			t.Errorf("history mismatch: %v", history[0])
+		}
+		if history[1].Subject != "Move test device" || history[1].Author != "Tester" {
+			t.Errorf("history mismatch: %v", history[1])
+		}
+		if c := history[1].Changes; len(c) != 3 || c[0].Fields[0].Field != "address" || c[0].Fields[0].New != "\"192.168.1.3/28\"" {
+			t.Errorf("history changes mismatch: [\n%s\n]", c)
+		}
+	}
+
+	t.Log("Check device address history.")
+	{
+		history, err := History(dir, "test-abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `- address test-abc, 192.168.1.1
++ address test-abc, 192.168.1.3`
+
+		var addresses Changes
+		for _, c := range history[len(history)-1].Changes {
+			if c.Kind == "address" {
+				addresses = append(addresses, c)
+			}
+		}
+		if addresses.String() != expected {
+			t.Errorf("address history mismatch: [\n%s\n]", SimpleDiff(addresses.String(), expected))
+		}
+	}
+
+	t.Log("Check history only walks metadata commits.")
+	{
+		history, err := History(dir, "other-abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range history {
+			if h.Subject == "Add notes" || h.Subject == "Break abc network" {
+				t.Errorf("unexpected history entry: %s", h.Subject)
+			}
+		}
+		if len(history) != 2 {
+			t.Errorf("history length mismatch: %d != 2", len(history))
+		}
+	}
+}
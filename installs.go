@@ -49,14 +49,100 @@ type DataloggerInstall struct {
 	Stop    time.Time `csv:"Installation Stop",`
 }
 
+// FirmwareInstall records the firmware version, and optional configuration revision, a device is
+// running from a given time.
+type FirmwareInstall struct {
+	Model   string    `csv:"Equipment Model"`
+	Serial  string    `csv:"Equipment Serial Number"`
+	Version string    `csv:"Firmware Version"`
+	Config  string    `csv:"Configuration Revision"`
+	Start   time.Time `csv:"Effective Time"`
+}
+
 type AssetList []Asset
 type RadioInstalls []RadioInstall
 type EquipmentInstalls []EquipmentInstall
 type SensorInstalls []SensorInstall
 type DataloggerInstalls []DataloggerInstall
+type FirmwareInstalls []FirmwareInstall
 
 func (a AssetList) List()          {}
 func (r RadioInstalls) List()      {}
 func (e EquipmentInstalls) List()  {}
 func (s SensorInstalls) List()     {}
 func (d DataloggerInstalls) List() {}
+func (f FirmwareInstalls) List()   {}
+
+// Installation is a generic view of an equipment installation record.
+type Installation struct {
+	Location string    `json:"location"`
+	Model    string    `json:"model"`
+	Serial   string    `json:"serial"`
+	Start    time.Time `json:"start"`
+	Stop     time.Time `json:"stop"`
+}
+
+// Installed returns whether the installation was in place at the given time.
+func (i Installation) Installed(at time.Time) bool {
+	return !i.Start.After(at) && i.Stop.After(at)
+}
+
+type Installations []Installation
+
+func (ii Installations) Len() int      { return len(ii) }
+func (ii Installations) Swap(i, j int) { ii[i], ii[j] = ii[j], ii[i] }
+func (ii Installations) Less(i, j int) bool {
+	switch {
+	case ii[i].Location != ii[j].Location:
+		return ii[i].Location < ii[j].Location
+	case ii[i].Model != ii[j].Model:
+		return ii[i].Model < ii[j].Model
+	case ii[i].Serial != ii[j].Serial:
+		return ii[i].Serial < ii[j].Serial
+	default:
+		return ii[i].Start.Before(ii[j].Start)
+	}
+}
+
+// radioStop marks radio installs, which have no installation times, as still in place.
+var radioStop = time.Date(9999, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Installer is implemented by install lists, radio lists have no installation times and are given a zero
+// start time with a stop time far in the future.
+type Installer interface {
+	Installations() []Installation
+}
+
+// Installations returns the radio installs, the radio list has no installation times so each radio
+// is taken to be installed at all times.
+func (r RadioInstalls) Installations() []Installation {
+	var ii []Installation
+	for _, v := range r {
+		ii = append(ii, Installation{Location: v.Location, Model: v.Model, Serial: v.Serial, Stop: radioStop})
+	}
+	return ii
+}
+
+func (e EquipmentInstalls) Installations() []Installation {
+	var ii []Installation
+	for _, v := range e {
+		ii = append(ii, Installation{Location: v.Location, Model: v.Model, Serial: v.Serial, Start: v.Start, Stop: v.Stop})
+	}
+	return ii
+}
+
+func (s SensorInstalls) Installations() []Installation {
+	var ii []Installation
+	for _, v := range s {
+		ii = append(ii, Installation{Location: v.Station, Model: v.Model, Serial: v.Serial, Start: v.Start, Stop: v.Stop})
+	}
+	return ii
+}
+
+func (d DataloggerInstalls) Installations() []Installation {
+	var ii []Installation
+	for _, v := range d {
+		ii = append(ii, Installation{Location: v.Station, Model: v.Model, Serial: v.Serial, Start: v.Start, Stop: v.Stop})
+	}
+	return ii
+}
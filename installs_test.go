@@ -236,3 +236,19 @@ func TestEquipmentInstalls_LoadFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestInstallation_Installed(t *testing.T) {
+	t.Log("Check installation times.")
+	{
+		i := Installation{
+			Start: MustParseTime("2010-01-01T00:00:00Z"),
+			Stop:  MustParseTime("2011-01-01T00:00:00Z"),
+		}
+		if !i.Installed(MustParseTime("2010-01-01T00:00:00Z")) {
+			t.Error("installation should include its start time")
+		}
+		if i.Installed(MustParseTime("2011-01-01T00:00:00Z")) {
+			t.Error("installation should exclude its stop time")
+		}
+	}
+}
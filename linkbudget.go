@@ -0,0 +1,159 @@
+package metadata
+
+import (
+	"math"
+	"sort"
+)
+
+// SpeedOfLight in metres per microsecond, which gives wavelengths in metres for frequencies in MHz.
+const SpeedOfLight = 299.792458
+
+// FreeSpacePathLoss returns the free space path loss in dB over a distance in kilometres at a frequency in MHz.
+func FreeSpacePathLoss(distance, frequency float64) float64 {
+	return 20.0*math.Log10(distance) + 20.0*math.Log10(frequency) + 32.44
+}
+
+// FresnelRadius returns the radius in metres of the first Fresnel zone at the midpoint of a path, for a
+// distance in kilometres and a frequency in MHz.
+func FresnelRadius(distance, frequency float64) float64 {
+	return math.Sqrt(SpeedOfLight / frequency * distance * 1000.0 / 4.0)
+}
+
+// LinkFrequency returns the frequency, in MHz, used by a link from the given location.
+type LinkFrequency func(location string, link Link) (float64, bool)
+
+// LinkBudget holds the path profile and expected signal levels of a radio link from one location to another.
+// Values which could not be found or calculated are left empty.
+type LinkBudget struct {
+	Location    string   `json:"location"`
+	Target      string   `json:"target"`
+	Role        *string  `json:"role,omitempty"`
+	Key         *string  `json:"key,omitempty"`
+	Polarity    *string  `json:"polarity,omitempty"`
+	Transmitter *string  `json:"transmitter,omitempty"`
+	Receiver    *string  `json:"receiver,omitempty"`
+	Distance    *float64 `json:"distance,omitempty"`
+	Bearing     *float64 `json:"bearing,omitempty"`
+	BackBearing *float64 `json:"back_bearing,omitempty"`
+	Frequency   *float64 `json:"frequency,omitempty"`
+	PathLoss    *float64 `json:"path_loss,omitempty"`
+	Fresnel     *float64 `json:"fresnel,omitempty"`
+	EIRP        *float64 `json:"eirp,omitempty"`
+	Received    *float64 `json:"received,omitempty"`
+	Margin      *float64 `json:"margin,omitempty"`
+}
+
+type LinkBudgets []LinkBudget
+
+func (l LinkBudgets) Len() int      { return len(l) }
+func (l LinkBudgets) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l LinkBudgets) Less(i, j int) bool {
+	if l[i].Location != l[j].Location {
+		return l[i].Location < l[j].Location
+	}
+	return l[i].Target < l[j].Target
+}
+
+// radioModels finds the radio model used at one location to reach another, either from the radio installs
+// or from the network devices which list the target amongst their links.
+func radioModels(radios RadioInstalls, networks []Network) map[[2]string]string {
+	models := make(map[[2]string]string)
+	for _, n := range networks {
+		for _, d := range n.Devices {
+			if d.Uninstalled != nil && *d.Uninstalled {
+				continue
+			}
+			for _, l := range d.Links {
+				models[[2]string{n.Location, l}] = d.Model
+			}
+		}
+	}
+	for _, r := range radios {
+		models[[2]string{r.Location, r.Target}] = r.Model
+	}
+	return models
+}
+
+// CalculateLinkBudgets works through each location's links, calculating the path profile and, where the
+// radio models and link frequency are known, the expected received signal and fade margin. Link frequencies
+// are only known when found by the frequency lookup, such as a frequency plan's LinkFrequency, which may be nil.
+func CalculateLinkBudgets(locations []Location, catalog *ModelCatalog, radios RadioInstalls, networks []Network, frequency LinkFrequency) LinkBudgets {
+	if frequency == nil {
+		frequency = func(string, Link) (float64, bool) { return 0.0, false }
+	}
+
+	points := make(map[string]Point)
+	for _, l := range locations {
+		if p, ok := l.Point(); ok {
+			points[l.Id] = p
+		}
+	}
+
+	version := func(name *string) *ModelVersion {
+		if catalog == nil || name == nil {
+			return nil
+		}
+		v, err := catalog.Resolve(*name)
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+
+	models := radioModels(radios, networks)
+
+	var budgets LinkBudgets
+	for _, l := range locations {
+		for _, k := range l.Links {
+			b := LinkBudget{
+				Location: l.Id,
+				Target:   k.Id,
+				Role:     k.Role,
+				Key:      k.Key,
+				Polarity: k.Polarity,
+			}
+
+			if m, ok := models[[2]string{l.Id, k.Id}]; ok {
+				b.Transmitter = &m
+			}
+			if m, ok := models[[2]string{k.Id, l.Id}]; ok {
+				b.Receiver = &m
+			}
+
+			from, ok := points[l.Id]
+			to, ok2 := points[k.Id]
+			if ok && ok2 {
+				d, fb, bb := Distance(from, to), Bearing(from, to), Bearing(to, from)
+				b.Distance, b.Bearing, b.BackBearing = &d, &fb, &bb
+			}
+
+			if f, ok := frequency(l.Id, k); ok {
+				b.Frequency = &f
+				if b.Distance != nil && *b.Distance > 0.0 {
+					loss, fresnel := FreeSpacePathLoss(*b.Distance, f), FresnelRadius(*b.Distance, f)
+					b.PathLoss, b.Fresnel = &loss, &fresnel
+				}
+			}
+
+			tx, rx := version(b.Transmitter), version(b.Receiver)
+			if tx != nil && tx.Transmit != nil && tx.Gain != nil {
+				eirp := *tx.Transmit + *tx.Gain
+				b.EIRP = &eirp
+				if rx != nil && rx.Gain != nil && b.PathLoss != nil {
+					received := eirp + *rx.Gain - *b.PathLoss
+					b.Received = &received
+					if rx.Sensitivity != nil {
+						margin := received - *rx.Sensitivity
+						b.Margin = &margin
+					}
+				}
+			}
+
+			budgets = append(budgets, b)
+		}
+	}
+
+	sort.Sort(budgets)
+
+	return budgets
+}
@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestLinkBudget_PathLoss(t *testing.T) {
+
+	t.Log("Check free space path loss.")
+	{
+		if l := FreeSpacePathLoss(10.0, 5800.0); math.Abs(l-127.71) > 0.01 {
+			t.Errorf("path loss mismatch: %g", l)
+		}
+		if l := FreeSpacePathLoss(1.0, 1.0); math.Abs(l-32.44) > 1e-9 {
+			t.Errorf("path loss mismatch: %g", l)
+		}
+	}
+
+	t.Log("Check midpoint fresnel radius.")
+	{
+		if r := FresnelRadius(10.0, 5800.0); math.Abs(r-11.37) > 0.01 {
+			t.Errorf("fresnel radius mismatch: %g", r)
+		}
+	}
+}
+
+func TestLinkBudget_CalculateLinkBudgets(t *testing.T) {
+
+	catalog, err := NewModelCatalog([]Model{testModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := testGeoLocation("A", -41.0, 175.0)
+	b := testGeoLocation("B", -41.0, 175.1)
+	c := testGeoLocation("C", -41.5, 175.0)
+
+	a.Links = []Link{
+		{Id: "B", Key: &[]string{"5800"}[0], Role: &[]string{"Master"}[0]},
+		{Id: "C", Key: &[]string{"K1"}[0]},
+		{Id: "D"},
+	}
+	b.Links = []Link{{Id: "A", Key: &[]string{"5800"}[0]}}
+
+	radios := RadioInstalls{
+		{Location: "A", Target: "B", Model: "Model B"},
+		{Location: "B", Target: "A", Model: "Model B"},
+	}
+	networks := []Network{
+		{Location: "A", Devices: []Device{{Name: "radio", Model: "Model A", Links: []string{"C"}}}},
+	}
+
+	keys, err := NewFrequencyKeys([]FrequencyPlan{{
+		Frequencies: []Frequency{{Key: "5800", Centre: &[]float64{5800.0}[0]}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budgets := CalculateLinkBudgets([]Location{b, a, c}, catalog, radios, networks, keys.LinkFrequency)
+
+	t.Log("Check link budget ordering.")
+	{
+		var ids []string
+		for _, l := range budgets {
+			ids = append(ids, l.Location+">"+l.Target)
+		}
+		if s := fmt.Sprint(ids); s != "[A>B A>C A>D B>A]" {
+			t.Errorf("link budget order mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check full link budget.")
+	{
+		l := budgets[0]
+		if l.Distance == nil || math.Abs(*l.Distance-Distance(Point{-41.0, 175.0}, Point{-41.0, 175.1})) > 1e-9 {
+			t.Fatal("missing link distance")
+		}
+		if l.Bearing == nil || math.Abs(*l.Bearing-90.0) > 0.1 || l.BackBearing == nil || math.Abs(*l.BackBearing-270.0) > 0.1 {
+			t.Errorf("link bearing mismatch")
+		}
+		if l.PathLoss == nil || *l.PathLoss != FreeSpacePathLoss(*l.Distance, 5800.0) {
+			t.Error("link path loss mismatch")
+		}
+		if l.EIRP == nil || *l.EIRP != 43.0 {
+			t.Error("link eirp mismatch")
+		}
+		if l.Received == nil || math.Abs(*l.Received-(66.0-*l.PathLoss)) > 1e-9 {
+			t.Error("link received level mismatch")
+		}
+		if l.Margin == nil || math.Abs(*l.Margin-(*l.Received+85.0)) > 1e-9 {
+			t.Error("link margin mismatch")
+		}
+	}
+
+	t.Log("Check partial link budgets.")
+	{
+		if l := budgets[1]; l.Transmitter == nil || *l.Transmitter != "Model A" || l.Frequency != nil || l.PathLoss != nil || l.Distance == nil {
+			t.Error("link without a frequency mismatch")
+		}
+		if l := budgets[2]; l.Distance != nil || l.EIRP != nil {
+			t.Error("link to an unknown location mismatch")
+		}
+	}
+
+	t.Log("Check link budgets without a frequency plan.")
+	{
+		for _, l := range CalculateLinkBudgets([]Location{b, a, c}, catalog, radios, networks, nil) {
+			if l.Frequency != nil || l.PathLoss != nil || l.Received != nil {
+				t.Errorf("numeric link key %s>%s should not give a frequency", l.Location, l.Target)
+			}
+		}
+	}
+}
@@ -114,19 +114,7 @@ func Encode(list List) ([][]string, error) {
 		v := rv.Index(i).Interface()
 		ri := reflect.ValueOf(v)
 		if i == 0 {
-			var header []string
-			t := reflect.TypeOf(v)
-			for j := 0; j < t.NumField(); j++ {
-				f := t.Field(j)
-				tags := strings.SplitAfter(strings.TrimSpace(f.Tag.Get("csv")), ",")
-				if len(tags) > 0 && len(tags[0]) > 0 {
-					header = append(header, tags[0])
-				} else {
-					header = append(header, strings.Title(f.Name))
-				}
-			}
-
-			data = append(data, header)
+			data = append(data, listHeader(reflect.TypeOf(v)))
 		}
 		var line []string
 		for j := 0; j < ri.NumField(); j++ {
@@ -148,7 +136,23 @@ func Encode(list List) ([][]string, error) {
 	return data, nil
 }
 
-func LoadList(path string, list List) error {
+// listHeader returns the CSV column headers for a list entry type.
+func listHeader(t reflect.Type) []string {
+	var header []string
+	for j := 0; j < t.NumField(); j++ {
+		f := t.Field(j)
+		tags := strings.SplitAfter(strings.TrimSpace(f.Tag.Get("csv")), ",")
+		if len(tags) > 0 && len(tags[0]) > 0 {
+			header = append(header, tags[0])
+		} else {
+			header = append(header, strings.Title(f.Name))
+		}
+	}
+	return header
+}
+
+// LoadList reads a CSV file into the list, any column mappings are applied in turn before decoding.
+func LoadList(path string, list List, mappings ...Mapping) error {
 
 	file, err := os.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
@@ -161,6 +165,12 @@ func LoadList(path string, list List) error {
 		return err
 	}
 
+	for _, m := range mappings {
+		if data, err = m.Apply(data, list); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
 	if err := Decode(data, list); err != nil {
 		return err
 	}
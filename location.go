@@ -1,50 +1,145 @@
 package metadata
 
 import (
-	"bytes"
 	"os"
 	"path/filepath"
-	"text/template"
-
-	"github.com/BurntSushi/toml"
 )
 
 const locationTemplate = `# Equipment location site information.
 
 ## The unique site specific single word id.
-id = "{{.Id}}"
+id = "{{Escape .Id}}"
 
 ## The general name of the location.
-name = "{{.Name}}"
+name = "{{Escape .Name}}"
 
 ## Geographical position.
 {{if .Latitude}}latitude = {{LatLon .Latitude}}{{else}}#latitude = degrees{{end}}
 {{if .Longitude}}longitude = {{LatLon .Longitude}}{{else}}#longitude = degrees{{end}}
 
+## Geodetic datum or coordinate reference system of the position, e.g. "WGS84" or "EPSG:4167".
+{{if .Datum}}datum = "{{Escape .Datum}}"{{else}}#datum = ""{{end}}
+
+## Height above the datum's reference surface in metres.
+{{if .Elevation}}elevation = {{Float .Elevation}}{{else}}#elevation = metres{{end}}
+
+## Height of the equipment relative to the ground surface in metres, negative values are below ground.
+{{if .Ground}}ground = {{Float .Ground}}{{else}}#ground = metres{{end}}
+
 ## An array of service providers associated with this location.
 {{if .Services}}services = [{{range $n, $t := .Services}}{{if gt $n 0}},{{end}}
-    "{{$t}}"{{end}}
+    "{{Escape $t}}"{{end}}
 ]{{else}}#services = []{{end}}
 
 ## An array of tags associated with this location.
 {{if .Tags}}tags = [{{range $n, $t := .Tags}}{{if gt $n 0}},{{end}}
-    "{{$t}}"{{end}}
+    "{{Escape $t}}"{{end}}
 ]{{else}}#tags = []{{end}}
 
 ## Access notes and documentation.
 {{if .Access}}access = """\
-{{$lines := Lines .Access}}{{range $k, $v := $lines}}    {{$v}}\n\
+{{$lines := Lines .Access}}{{range $k, $v := $lines}}    {{Escape $v}}\n\
 {{end}}    """{{else}}#access = """\
 #    \n\
 #    """{{end}}
 
 ## Location notes and documentation.
 {{if .Notes}}notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{Escape $v}}\n\
 {{end}}    """{{else}}#notes = """\
 #    \n\
 #    """{{end}}
 
+## Structured site access details.
+{{if .SiteAccess}}
+[site_access]
+    ## Gate code reference, the codes themselves are kept elsewhere.
+{{if .SiteAccess.Gate}}    gate = "{{Escape .SiteAccess.Gate}}"{{else}}    #gate = ""{{end}}
+
+    ## An array of key numbers needed for access.
+{{if .SiteAccess.Keys}}    keys = [{{range $n, $t := .SiteAccess.Keys}}{{if gt $n 0}},{{end}}
+        "{{Escape $t}}"{{end}}
+    ]{{else}}    #keys = []{{end}}
+
+    ## Landowner name.
+{{if .SiteAccess.Landowner}}    landowner = "{{Escape .SiteAccess.Landowner}}"{{else}}    #landowner = ""{{end}}
+
+    ## Landowner contact details.
+{{if .SiteAccess.Contact}}    contact = "{{Escape .SiteAccess.Contact}}"{{else}}    #contact = ""{{end}}
+
+    ## Is a four wheel drive vehicle needed.
+{{if .SiteAccess.FourWheelDrive}}    four_wheel_drive = {{.SiteAccess.FourWheelDrive}}{{else}}    #four_wheel_drive = true|false{{end}}
+
+    ## Is a helicopter needed.
+{{if .SiteAccess.Helicopter}}    helicopter = {{.SiteAccess.Helicopter}}{{else}}    #helicopter = true|false{{end}}
+
+    ## An array of known site hazards.
+{{if .SiteAccess.Hazards}}    hazards = [{{range $n, $t := .SiteAccess.Hazards}}{{if gt $n 0}},{{end}}
+        "{{Escape $t}}"{{end}}
+    ]{{else}}    #hazards = []{{end}}
+
+    ## An array of seasonal or other access restrictions.
+{{if .SiteAccess.Restrictions}}    restrictions = [{{range $n, $t := .SiteAccess.Restrictions}}{{if gt $n 0}},{{end}}
+        "{{Escape $t}}"{{end}}
+    ]{{else}}    #restrictions = []{{end}}{{else}}
+#[site_access]
+#    ## Gate code reference, the codes themselves are kept elsewhere.
+#    #gate = ""
+#
+#    ## An array of key numbers needed for access.
+#    #keys = []
+#
+#    ## Landowner name.
+#    #landowner = ""
+#
+#    ## Landowner contact details.
+#    #contact = ""
+#
+#    ## Is a four wheel drive vehicle needed.
+#    #four_wheel_drive = true|false
+#
+#    ## Is a helicopter needed.
+#    #helicopter = true|false
+#
+#    ## An array of known site hazards.
+#    #hazards = []
+#
+#    ## An array of seasonal or other access restrictions.
+#    #restrictions = []{{end}}
+
+## Site power supply capacity.
+{{if .Power}}
+[power]
+    ## Is the site mains powered.
+{{if .Power.Mains}}    mains = {{.Power.Mains}}{{else}}    #mains = true|false{{end}}
+
+    ## Solar panel capacity in watts.
+{{if .Power.Solar}}    solar = {{Float .Power.Solar}}{{else}}    #solar = watts{{end}}
+
+    ## Expected daily peak sun hours.
+{{if .Power.Insolation}}    insolation = {{Float .Power.Insolation}}{{else}}    #insolation = hours{{end}}
+
+    ## Battery capacity in watt hours.
+{{if .Power.Battery}}    battery = {{Float .Power.Battery}}{{else}}    #battery = watt hours{{end}}
+
+    ## Required battery autonomy in hours.
+{{if .Power.Autonomy}}    autonomy = {{Float .Power.Autonomy}}{{else}}    #autonomy = hours{{end}}{{else}}
+#[power]
+#    ## Is the site mains powered.
+#    #mains = true|false
+#
+#    ## Solar panel capacity in watts.
+#    #solar = watts
+#
+#    ## Expected daily peak sun hours.
+#    #insolation = hours
+#
+#    ## Battery capacity in watt hours.
+#    #battery = watt hours
+#
+#    ## Required battery autonomy in hours.
+#    #autonomy = hours{{end}}
+
 ## Linked locations.
 
 #[[link]]
@@ -62,20 +157,39 @@ name = "{{.Name}}"
 
 [[link]]
     ## Remote location.
-    id = "{{.Id}}"
+    id = "{{Escape .Id}}"
 
     ## Radio role.
-{{if .Role}}    role = "{{.Role}}"{{else}}#role = ""{{end}}
+{{if .Role}}    role = "{{Escape .Role}}"{{else}}#role = ""{{end}}
 
     ## Frequency key.
-{{if .Key}}    key = "{{.Key}}"{{else}}#key = ""{{end}}
+{{if .Key}}    key = "{{Escape .Key}}"{{else}}#key = ""{{end}}
 
     ## Antenna polarity.
-{{if .Polarity}}    polarity = "{{.Polarity}}"{{else}}#polarity = ""{{end}}{{end}}
+{{if .Polarity}}    polarity = "{{Escape .Polarity}}"{{else}}#polarity = ""{{end}}{{end}}
 
 # vim: tabstop=4 expandtab shiftwidth=4 softtabstop=4
 `
 
+type Power struct {
+	Mains      *bool    `json:"mains,omitempty"`
+	Solar      *float64 `json:"solar,omitempty"`
+	Insolation *float64 `json:"insolation,omitempty"`
+	Battery    *float64 `json:"battery,omitempty"`
+	Autonomy   *float64 `json:"autonomy,omitempty"`
+}
+
+type SiteAccess struct {
+	Gate           *string  `json:"gate,omitempty"`
+	Keys           []string `json:"keys,omitempty"`
+	Landowner      *string  `json:"landowner,omitempty"`
+	Contact        *string  `json:"contact,omitempty"`
+	FourWheelDrive *bool    `json:"four_wheel_drive,omitempty" toml:"four_wheel_drive"`
+	Helicopter     *bool    `json:"helicopter,omitempty"`
+	Hazards        []string `json:"hazards,omitempty"`
+	Restrictions   []string `json:"restrictions,omitempty"`
+}
+
 type Link struct {
 	Id       string  `json:"id"`
 	Role     *string `json:"role"`
@@ -84,21 +198,26 @@ type Link struct {
 }
 
 type Location struct {
-	Id        string   `json:"id"`
-	Name      string   `json:"name"`
-	Latitude  *float32 `json:"latitude,omitempty"`
-	Longitude *float32 `json:"longitude,omitempty"`
-	Services  []string `json:"services,omitempty"`
-	Tags      []string `json:"tags,omitempty"`
-	Access    *string  `json:"access,omitempty"`
-	Links     []Link   `json:"links,omitempty" toml:"link"`
-	Notes     *string  `json:"notes,omitempty"`
+	Id         string      `json:"id"`
+	Name       string      `json:"name"`
+	Latitude   *float64    `json:"latitude,omitempty"`
+	Longitude  *float64    `json:"longitude,omitempty"`
+	Datum      *string     `json:"datum,omitempty"`
+	Elevation  *float64    `json:"elevation,omitempty"`
+	Ground     *float64    `json:"ground,omitempty"`
+	Services   []string    `json:"services,omitempty"`
+	Tags       []string    `json:"tags,omitempty"`
+	Access     *string     `json:"access,omitempty"`
+	SiteAccess *SiteAccess `json:"site_access,omitempty" toml:"site_access"`
+	Power      *Power      `json:"power,omitempty"`
+	Links      []Link      `json:"links,omitempty" toml:"link"`
+	Notes      *string     `json:"notes,omitempty"`
 }
 
 func LoadLocation(filename string) (*Location, error) {
 	var l Location
 
-	if _, err := toml.DecodeFile(filename, &l); err != nil {
+	if err := DecodeFile(filename, &l); err != nil {
 		return nil, err
 	}
 
@@ -109,7 +228,7 @@ func LoadLocations(dirname, filename string) ([]Location, error) {
 	var ll []Location
 
 	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
-		if err == nil && filepath.Base(path) == filename {
+		if err == nil && metadataName(filepath.Base(path)) == filename {
 			l, e := LoadLocation(path)
 			if e != nil {
 				return e
@@ -126,41 +245,9 @@ func LoadLocations(dirname, filename string) ([]Location, error) {
 }
 
 func (loc Location) StoreLocation(path string) error {
-
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write([]byte(loc.String()))
-	if err != nil {
-		return err
-	}
-
-	return err
+	return StoreFile(path, loc)
 }
 
 func (loc Location) String() string {
-	tplFuncMap := make(template.FuncMap)
-	tplFuncMap["Lines"] = Lines
-	tplFuncMap["LatLon"] = LatLon
-
-	tmpl, err := template.New("").Funcs(tplFuncMap).Parse(locationTemplate)
-	if err != nil {
-		panic(err)
-	}
-
-	var doc bytes.Buffer
-	err = tmpl.Execute(&doc, loc)
-	if err != nil {
-		panic(err)
-	}
-
-	return doc.String()
-
+	return mustExecute(LocationTemplate, loc)
 }
@@ -13,11 +13,28 @@ var testLocation Location
 func init() {
 
 	testLocation = Location{
-		Id:       "location",
-		Name:     "A Location Name",
-		Latitude: &[]float32{-41.5}[0],
-		Services: []string{"Test 1 Service", "Test 2 Service"},
-		Tags:     []string{"ABC", "DEF"},
+		Id:        "location",
+		Name:      "A Location Name",
+		Latitude:  &[]float64{-41.5}[0],
+		Longitude: &[]float64{174.123456789}[0],
+		Datum:     &[]string{"WGS84"}[0],
+		Elevation: &[]float64{120.5}[0],
+		Services:  []string{"Test 1 Service", "Test 2 Service"},
+		Tags:      []string{"ABC", "DEF"},
+		SiteAccess: &SiteAccess{
+			Gate:           &[]string{"Gate Book 1"}[0],
+			Keys:           []string{"K12", "K14"},
+			Landowner:      &[]string{"A Landowner"}[0],
+			Contact:        &[]string{"021 000 0000"}[0],
+			FourWheelDrive: &[]bool{true}[0],
+			Hazards:        []string{"Steep drop at the gate"},
+			Restrictions:   []string{"No access during lambing"},
+		},
+		Power: &Power{
+			Solar:      &[]float64{120}[0],
+			Insolation: &[]float64{3.5}[0],
+			Battery:    &[]float64{2400}[0],
+		},
 		Links: []Link{
 			Link{
 				Id:       "somewhere",
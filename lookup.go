@@ -0,0 +1,46 @@
+package metadata
+
+import (
+	"net"
+)
+
+// AddressMatch describes where an IP address has been found in the metadata.
+type AddressMatch struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Location string `json:"location,omitempty"`
+	Network  string `json:"network"`
+}
+
+// LookupIP finds the devices, device aliases, site runnets and provider ranges which match or contain the given address.
+func (t *Tree) LookupIP(ip net.IP) []AddressMatch {
+	var matches []AddressMatch
+
+	for _, n := range t.Networks {
+		for _, d := range n.Devices {
+			if d.Address != nil && d.Address.IP.Equal(ip) {
+				matches = append(matches, AddressMatch{Kind: "device", Name: d.Name, Location: n.Location, Network: d.Address.String()})
+			}
+			for _, a := range d.Aliases {
+				if a.IP.Equal(ip) {
+					matches = append(matches, AddressMatch{Kind: "alias", Name: d.Name, Location: n.Location, Network: a.String()})
+				}
+			}
+		}
+		if n.Runnet != nil && n.Runnet.Contains(ip) {
+			matches = append(matches, AddressMatch{Kind: "runnet", Name: n.Location, Location: n.Location, Network: n.Runnet.String()})
+		}
+	}
+
+	for _, p := range t.Providers {
+		for _, r := range p.Ranges {
+			for _, n := range r.Networks {
+				if n.Contains(ip) {
+					matches = append(matches, AddressMatch{Kind: "range", Name: p.Name + ": " + r.Name, Network: n.String()})
+				}
+			}
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,136 @@
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Mapping describes how a CSV file exported from another system can be read as one of the install lists.
+// All entries are keyed by the list's own column header, e.g. "Equipment Location".
+type Mapping struct {
+	// Columns gives the foreign header to read for a column, columns not given use their own header.
+	Columns map[string]string `toml:"columns"`
+	// Formats gives the time layout, in Go's reference time format, used by a foreign date column.
+	Formats map[string]string `toml:"formats"`
+	// TimeZone is the zone used for dates which have no zone information of their own, UTC if not given.
+	TimeZone string `toml:"timezone"`
+	// Transforms lists the named transforms to apply to a column, in order: upper, lower, title and trim.
+	Transforms map[string][]string `toml:"transforms"`
+	// Values replaces specific foreign values in a column, after any transforms.
+	Values map[string]map[string]string `toml:"values"`
+	// Defaults is used for empty values, or for columns missing from the foreign file.
+	Defaults map[string]string `toml:"defaults"`
+}
+
+var mappingTransforms = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": strings.Title,
+	"trim":  strings.TrimSpace,
+}
+
+// LoadMapping reads a column mapping config file.
+func LoadMapping(path string) (*Mapping, error) {
+	var m Mapping
+	if _, err := toml.DecodeFile(path, &m); err != nil {
+		return nil, err
+	}
+	if err := m.check(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &m, nil
+}
+
+func (m Mapping) check() error {
+	for k, v := range m.Transforms {
+		for _, t := range v {
+			if _, ok := mappingTransforms[t]; !ok {
+				return fmt.Errorf("unknown transform for %s: %s", k, t)
+			}
+		}
+	}
+	if m.TimeZone != "" {
+		if _, err := time.LoadLocation(m.TimeZone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply converts foreign CSV data, including its header line, into the columns expected by the given list.
+func (m Mapping) Apply(data [][]string, list List) ([][]string, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(list))
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("list mapping requires a slice of structs")
+	}
+	header := listHeader(rv.Type().Elem())
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	zone := time.UTC
+	if m.TimeZone != "" {
+		zone, _ = time.LoadLocation(m.TimeZone)
+	}
+
+	// find which foreign column is used for each list column
+	index := make(map[string]int)
+	for i, h := range data[0] {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	columns := make([]int, len(header))
+	for i, h := range header {
+		name := h
+		if c, ok := m.Columns[h]; ok {
+			name = c
+		}
+		n, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if _, ok := m.Defaults[h]; !ok {
+				return nil, fmt.Errorf("missing column: %s", name)
+			}
+			n = -1
+		}
+		columns[i] = n
+	}
+
+	mapped := [][]string{header}
+	for l, row := range data[1:] {
+		line := make([]string, len(header))
+		for i, h := range header {
+			var s string
+			if n := columns[i]; n >= 0 && n < len(row) {
+				s = strings.TrimSpace(row[n])
+			}
+			for _, t := range m.Transforms[h] {
+				s = mappingTransforms[t](s)
+			}
+			if v, ok := m.Values[h][s]; ok {
+				s = v
+			}
+			switch {
+			case s == "":
+				s = m.Defaults[h]
+			case m.Formats[h] != "":
+				t, err := time.ParseInLocation(m.Formats[h], s, zone)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s: %v", l+2, h, err)
+				}
+				s = t.UTC().Format(DateTimeFormat)
+			}
+			line[i] = s
+		}
+		mapped = append(mapped, line)
+	}
+
+	return mapped, nil
+}
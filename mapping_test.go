@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestMapping_LoadList(t *testing.T) {
+
+	mapping, err := LoadMapping("testdata/equipment-export.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check loading a mapped equipment installs file.")
+	{
+		var installs EquipmentInstalls
+		if err := LoadList("testdata/equipment-export.csv", &installs, *mapping); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := EquipmentInstalls{
+			EquipmentInstall{
+				Location: "Somewhere",
+				Model:    "Model #1",
+				Serial:   "Serial #1",
+				Start:    MustParseTime("2010-01-01T00:00:00Z"),
+				Stop:     MustParseTime("2011-01-01T00:00:00Z"),
+			},
+			EquipmentInstall{
+				Location: "Somewhere",
+				Model:    "Model #2",
+				Serial:   "Serial #2",
+				Start:    MustParseTime("2010-01-01T00:00:00Z"),
+				Stop:     MustParseTime("9999-01-01T00:00:00Z"),
+			},
+			EquipmentInstall{
+				Location: "Somewhere Else",
+				Model:    "Model #2",
+				Serial:   "Serial #2",
+				Start:    MustParseTime("2012-01-01T00:00:00Z"),
+				Stop:     MustParseTime("2013-01-01T00:00:00Z"),
+			},
+		}
+		if Strings(installs) != Strings(expected) {
+			t.Errorf("mapped equipment installs mismatch: [\n%s\n]", SimpleDiff(Strings(installs), Strings(expected)))
+		}
+	}
+
+	t.Log("Check mapping with missing columns.")
+	{
+		data := [][]string{{"SITE_CODE"}, {"somewhere"}}
+
+		var installs EquipmentInstalls
+		if _, err := mapping.Apply(data, &installs); err == nil {
+			t.Error("expected missing column error")
+		}
+	}
+
+	t.Log("Check mapping with bad dates.")
+	{
+		data := [][]string{
+			{"SITE_CODE", "MAKE_MODEL", "SERIAL_NO", "DATE_INSTALLED", "DATE_REMOVED"},
+			{"somewhere", "MODEL #1", "Serial #1", "2010-01-01", ""},
+		}
+
+		var installs EquipmentInstalls
+		if _, err := mapping.Apply(data, &installs); err == nil {
+			t.Error("expected date format error")
+		}
+	}
+
+	t.Log("Check mapping with unknown transforms.")
+	{
+		m := Mapping{Transforms: map[string][]string{"Equipment Model": {"reverse"}}}
+
+		var installs EquipmentInstalls
+		if _, err := m.Apply([][]string{{"Equipment Model"}}, &installs); err == nil {
+			t.Error("expected unknown transform error")
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package metadata
 
 import (
+	"bytes"
 	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -33,11 +38,73 @@ func (k Keys) Less(i, j int) bool { return k[i] < k[j] }
 func (k Keys) get(i int) string   { return k[i] }
 
 func Lines(notes string) []string {
-	return strings.Split(strings.TrimSpace(strings.Replace(notes, "\r\n", "\n", -1)), "\n")
+	lines := strings.Split(strings.TrimSpace(notes), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	return lines
+}
+
+// LatLon formats a position using at least four decimal places, any extra precision is kept.
+func LatLon(latlon *float64) string {
+	s := strconv.FormatFloat(*latlon, 'f', 4, 64)
+	if f, err := strconv.ParseFloat(s, 64); err != nil || f != *latlon {
+		s = strconv.FormatFloat(*latlon, 'f', -1, 64)
+	}
+	return s
+}
+
+// Float formats a value so that it will always be decoded as a TOML float, values which are not finite
+// cannot be stored and are reported by the tree validation.
+func Float(f *float64) string {
+	s := strconv.FormatFloat(*f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s = s + ".0"
+	}
+	return s
+}
+
+// Escape formats text for use inside a TOML basic, or multi-line basic, string.
+func Escape(text string) string {
+	var b bytes.Buffer
+	for _, r := range text {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, "\\u%04X", r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
 }
 
-func LatLon(latlon *float32) string {
-	return fmt.Sprintf("%.4f", *latlon)
+// Key formats text for use as a TOML key, it is quoted unless it is a valid bare key.
+func Key(text string) string {
+	for _, r := range text {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return "\"" + Escape(text) + "\""
+		}
+	}
+	if text == "" {
+		return `""`
+	}
+	return text
 }
 
 // simple debugging helper function
@@ -98,3 +165,56 @@ func SimpleDiff(s1, s2 string) string {
 
 	return strings.Join(s, "\n")
 }
+
+// nonFinite returns the names, as stored, of any NaN or infinite float fields found in a value.
+func nonFinite(v interface{}) []string {
+	var names []string
+
+	var walk func(name string, rv reflect.Value)
+	walk = func(name string, rv reflect.Value) {
+		switch rv.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if !rv.IsNil() {
+				walk(name, rv.Elem())
+			}
+		case reflect.Float32, reflect.Float64:
+			if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+				names = append(names, name)
+			}
+		case reflect.Struct:
+			for i := 0; i < rv.NumField(); i++ {
+				f := rv.Type().Field(i)
+				if f.PkgPath != "" {
+					continue
+				}
+				n := strings.Split(f.Tag.Get("toml"), ",")[0]
+				if n == "" {
+					n = strings.Split(f.Tag.Get("json"), ",")[0]
+				}
+				if n == "" {
+					n = strings.ToLower(f.Name)
+				}
+				switch {
+				case f.Anonymous:
+					n = name
+				case name != "":
+					n = name + "." + n
+				}
+				walk(n, rv.Field(i))
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				walk(fmt.Sprintf("%s[%d]", name, i), rv.Index(i))
+			}
+		case reflect.Map:
+			keys := rv.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+			for _, k := range keys {
+				walk(fmt.Sprintf("%s.%v", name, k), rv.MapIndex(k))
+			}
+		}
+	}
+	walk("", reflect.ValueOf(v))
+
+	return names
+}
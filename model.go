@@ -1,23 +1,19 @@
 package metadata
 
 import (
-	"bytes"
 	"os"
 	"path/filepath"
-	"text/template"
-
-	"github.com/BurntSushi/toml"
 )
 
 const modelTemplate = `## The name of the equipment model.
-name = "{{.Name}}"
+name = "{{Escape .Name}}"
 
 ## Primary device manufacturer.
-manufacturer = "{{.Manufacturer}}"
+manufacturer = "{{Escape .Manufacturer}}"
 
 ## Optional model specific notes and documentation.
 notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{Escape $v}}\n\
 {{end}}    """
 
 ## A list of device model versions.
@@ -32,26 +28,94 @@ notes = """\
 #    ## An array of extra tags associated with this version.
 #    #tags = []
 #
+#    ## Nominal power consumption in watts.
+#    #power = watts
+#
+#    ## Supply voltage range in volts.
+#    #min_voltage = volts
+#    #max_voltage = volts
+#
+#    ## Device mass in kilograms.
+#    #mass = kilograms
+#
+#    ## Radio antenna gain in dBi.
+#    #gain = dBi
+#
+#    ## Radio transmit power in dBm.
+#    #transmit = dBm
+#
+#    ## Radio receiver sensitivity in dBm.
+#    #sensitivity = dBm
+#
+#    ## An array of network ports.
+#    #ports = []
+#
+#    ## An array of supported firmware versions.
+#    #firmware = []
+#
+#    ## The recommended firmware version.
+#    #recommended = ""
+#
+#    ## An array of datasheet references.
+#    #datasheets = []
+#
 #    ## Optional model version specific notes and documentation.
 #    #notes = """\
 #    #    \n\
 #    #    """{{range $k, $v := .Versions}}
 
-[version.{{$k}}]
+[version.{{Key $k}}]
     ## The name of the device model.
-    name = "{{$v.Name}}"
+    name = "{{Escape $v.Name}}"
 
     ## The generic type of the model version.
-    type = "{{$v.Type}}"
+    type = "{{Escape $v.Type}}"
 
     ## An array of extra tags associated with this version.
 {{if $v.Tags}}    tags = [{{range $n, $t := $v.Tags}}{{if gt $n 0}},{{end}}
-        "{{$t}}"{{end}}
+        "{{Escape $t}}"{{end}}
     ]{{else}}    #tags = []{{end}}
 
+    ## Nominal power consumption in watts.
+{{if $v.Power}}    power = {{Float $v.Power}}{{else}}    #power = watts{{end}}
+
+    ## Supply voltage range in volts.
+{{if $v.MinVoltage}}    min_voltage = {{Float $v.MinVoltage}}{{else}}    #min_voltage = volts{{end}}
+{{if $v.MaxVoltage}}    max_voltage = {{Float $v.MaxVoltage}}{{else}}    #max_voltage = volts{{end}}
+
+    ## Device mass in kilograms.
+{{if $v.Mass}}    mass = {{Float $v.Mass}}{{else}}    #mass = kilograms{{end}}
+
+    ## Radio antenna gain in dBi.
+{{if $v.Gain}}    gain = {{Float $v.Gain}}{{else}}    #gain = dBi{{end}}
+
+    ## Radio transmit power in dBm.
+{{if $v.Transmit}}    transmit = {{Float $v.Transmit}}{{else}}    #transmit = dBm{{end}}
+
+    ## Radio receiver sensitivity in dBm.
+{{if $v.Sensitivity}}    sensitivity = {{Float $v.Sensitivity}}{{else}}    #sensitivity = dBm{{end}}
+
+    ## An array of network ports.
+{{if $v.Ports}}    ports = [{{range $n, $t := $v.Ports}}{{if gt $n 0}},{{end}}
+        "{{Escape $t}}"{{end}}
+    ]{{else}}    #ports = []{{end}}
+
+    ## An array of supported firmware versions.
+{{if $v.Firmware}}    firmware = [{{range $n, $t := $v.Firmware}}{{if gt $n 0}},{{end}}
+        "{{Escape $t}}"{{end}}
+    ]{{else}}    #firmware = []{{end}}
+
+    ## The recommended firmware version.
+{{if $v.Recommended}}    recommended = "{{Escape $v.Recommended}}"{{else}}    #recommended = ""{{end}}
+
+    ## An array of datasheet references.
+{{if $v.Datasheets}}    datasheets = [{{range $n, $t := $v.Datasheets}}{{if gt $n 0}},{{end}}
+        "{{Escape $t}}"{{end}}
+    ]{{else}}    #datasheets = []{{end}}
+
     ## Optional model version specific notes and documentation.
 {{if $v.Notes}}    notes = """\
-{{$lines := Lines $v.Notes}}{{range $k, $v := $lines}}        {{$v}}\n\
+{{$lines := Lines $v.Notes}}{{range $k, $v := $lines}}        {{Escape $v}}\n\
 {{end}}        """{{else}}    #notes = """\
     #    \n\
     #    """{{end}}{{end}}
@@ -60,10 +124,21 @@ notes = """\
 `
 
 type Version struct {
-	Name  string   `json:"name"`
-	Type  string   `json:"type"`
-	Notes *string  `json:"notes,omitempty"`
-	Tags  []string `json:"tags,omitempty"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Notes       *string  `json:"notes,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Power       *float64 `json:"power,omitempty"`
+	MinVoltage  *float64 `json:"min_voltage,omitempty" toml:"min_voltage"`
+	MaxVoltage  *float64 `json:"max_voltage,omitempty" toml:"max_voltage"`
+	Mass        *float64 `json:"mass,omitempty"`
+	Gain        *float64 `json:"gain,omitempty"`
+	Transmit    *float64 `json:"transmit,omitempty"`
+	Sensitivity *float64 `json:"sensitivity,omitempty"`
+	Ports       []string `json:"ports,omitempty"`
+	Firmware    []string `json:"firmware,omitempty"`
+	Recommended *string  `json:"recommended,omitempty"`
+	Datasheets  []string `json:"datasheets,omitempty"`
 }
 
 type Model struct {
@@ -76,7 +151,7 @@ type Model struct {
 func LoadModel(filename string) (*Model, error) {
 	var m Model
 
-	if _, err := toml.DecodeFile(filename, &m); err != nil {
+	if err := DecodeFile(filename, &m); err != nil {
 		return nil, err
 	}
 
@@ -87,7 +162,7 @@ func LoadModels(dirname, filename string) ([]Model, error) {
 	var mm []Model
 
 	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
-		if err == nil && filepath.Base(path) == filename {
+		if err == nil && metadataName(filepath.Base(path)) == filename {
 			m, e := LoadModel(path)
 			if e != nil {
 				return e
@@ -104,40 +179,9 @@ func LoadModels(dirname, filename string) ([]Model, error) {
 }
 
 func (mod Model) StoreModel(path string) error {
-
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write([]byte(mod.String()))
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return StoreFile(path, mod)
 }
 
 func (mod Model) String() string {
-
-	tplFuncMap := make(template.FuncMap)
-	tplFuncMap["Lines"] = Lines
-
-	tmpl, err := template.New("").Funcs(tplFuncMap).Parse(modelTemplate)
-	if err != nil {
-		panic(err)
-	}
-
-	var doc bytes.Buffer
-	err = tmpl.Execute(&doc, mod)
-	if err != nil {
-		panic(err)
-	}
-
-	return doc.String()
+	return mustExecute(ModelTemplate, mod)
 }
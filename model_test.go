@@ -21,9 +21,20 @@ func init() {
 				Type: "Model Type A",
 			},
 			"model_b": Version{
-				Name: "Model B",
-				Type: "Model Type B",
-				Tags: []string{"A", "B", "C"},
+				Name:        "Model B",
+				Type:        "Model Type B",
+				Tags:        []string{"A", "B", "C"},
+				Power:       &[]float64{2.5}[0],
+				MinVoltage:  &[]float64{10}[0],
+				MaxVoltage:  &[]float64{30}[0],
+				Mass:        &[]float64{1.25}[0],
+				Gain:        &[]float64{23}[0],
+				Transmit:    &[]float64{20}[0],
+				Sensitivity: &[]float64{-85}[0],
+				Ports:       []string{"eth0", "eth1"},
+				Firmware:    []string{"1.0.1", "1.2.0"},
+				Recommended: &[]string{"1.2.0"}[0],
+				Datasheets:  []string{"http://example.com/model_b.pdf"},
 			},
 			"model_c": Version{
 				Name:  "Model C",
@@ -1,26 +1,22 @@
 package metadata
 
 import (
-	"bytes"
 	"net"
 	"os"
 	"path/filepath"
-	"text/template"
-
-	"github.com/BurntSushi/toml"
 )
 
 const networkTemplate = `# Network and device IP address information.
 
 ## The network location ID tag.
-location = "{{.Location}}"
+location = "{{Escape .Location}}"
 
 ## Name of the network, defaults to location name.
-{{if .Name}}name = "{{.Name}}"{{else}}#name = ""{{end}}
+{{if .Name}}name = "{{Escape .Name}}"{{else}}#name = ""{{end}}
 
 ## Network notes and documentation.
 {{if .Notes}}notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{Escape $v}}\n\
 {{end}}    """{{else}}#notes = """\
 #    \n\
 #    """{{end}}
@@ -36,7 +32,7 @@ location = "{{.Location}}"
 
 [[linknet]]
     ## The name of the link, usually of the form "Remote Site to Local Site".
-    name = "{{.Name}}"{{end}}
+    name = "{{Escape .Name}}"{{end}}
 
 ## Local devices.
 
@@ -69,10 +65,10 @@ location = "{{.Location}}"
 
 [[device]]
     ## Device name, generally an equipment tag plus the site network tag.
-    name = "{{.Name}}"
+    name = "{{Escape .Name}}"
 
     ## Model name, a generic term useful for monitoring or configuration.
-    model = "{{.Model}}"
+    model = "{{Escape .Model}}"
 
     ## Primary IP address of the device.
 {{if .Address}}    address = "{{.Address}}"{{else}}    #address=""{{end}}
@@ -84,17 +80,17 @@ location = "{{.Location}}"
 
     ## Extra tags associated with this device.
 {{if .Tags}}    tags = [{{range $n, $t := .Tags}}{{if gt $n 0}},{{end}}
-        "{{$t}}"{{end}}
+        "{{Escape $t}}"{{end}}
     ]{{else}}    #tags = []{{end}}
 
     ## Linked devices.
 {{if .Links}}    links = [{{range $n, $t := .Links}}{{if gt $n 0}},{{end}}
-        "{{$t}}"{{end}}
+        "{{Escape $t}}"{{end}}
     ]{{else}}    #links = []{{end}}
 
     ## Device specific notes and documentation.
 {{if .Notes}}    notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{Escape $v}}\n\
 {{end}}        """{{else}}    #notes = """\
     #    \n\
     #    """{{end}}
@@ -199,7 +195,7 @@ type Network struct {
 func LoadNetwork(filename string) (*Network, error) {
 	var l Network
 
-	if _, err := toml.DecodeFile(filename, &l); err != nil {
+	if err := DecodeFile(filename, &l); err != nil {
 		return nil, err
 	}
 
@@ -210,7 +206,7 @@ func LoadNetworks(dirname, filename string) ([]Network, error) {
 	var ll []Network
 
 	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
-		if err == nil && filepath.Base(path) == filename {
+		if err == nil && metadataName(filepath.Base(path)) == filename {
 			l, e := LoadNetwork(path)
 			if e != nil {
 				return e
@@ -227,41 +223,9 @@ func LoadNetworks(dirname, filename string) ([]Network, error) {
 }
 
 func (net Network) StoreNetwork(path string) error {
-
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write([]byte(net.String()))
-	if err != nil {
-		return err
-	}
-
-	return err
+	return StoreFile(path, net)
 }
 
 func (net Network) String() string {
-	tplFuncMap := make(template.FuncMap)
-	tplFuncMap["Lines"] = Lines
-	tplFuncMap["LatLon"] = LatLon
-
-	tmpl, err := template.New("").Funcs(tplFuncMap).Parse(networkTemplate)
-	if err != nil {
-		panic(err)
-	}
-
-	var doc bytes.Buffer
-	err = tmpl.Execute(&doc, net)
-	if err != nil {
-		panic(err)
-	}
-
-	return doc.String()
-
+	return mustExecute(NetworkTemplate, net)
 }
@@ -0,0 +1,124 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ServiceImpact describes how a location is affected by an outage of one or more provider services.
+type ServiceImpact struct {
+	Location string   `json:"location"`
+	Services []string `json:"services,omitempty"`
+	Isolated bool     `json:"isolated"`
+	Devices  []string `json:"devices,omitempty"`
+}
+
+type ServiceImpacts []ServiceImpact
+
+func (s ServiceImpacts) Len() int           { return len(s) }
+func (s ServiceImpacts) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s ServiceImpacts) Less(i, j int) bool { return s[i].Location < s[j].Location }
+
+// reachable returns the locations which can reach an upstream location, either directly or by following
+// radio links in either direction.
+func reachable(locations []Location, upstream func(Location) bool) map[string]bool {
+	links := make(map[string][]string)
+	for _, l := range locations {
+		for _, k := range l.Links {
+			links[l.Id] = append(links[l.Id], k.Id)
+			links[k.Id] = append(links[k.Id], l.Id)
+		}
+	}
+
+	seen := make(map[string]bool)
+
+	var queue []string
+	for _, l := range locations {
+		if upstream(l) && !seen[l.Id] {
+			seen[l.Id] = true
+			queue = append(queue, l.Id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, k := range links[id] {
+			if !seen[k] {
+				seen[k] = true
+				queue = append(queue, k)
+			}
+		}
+	}
+
+	return seen
+}
+
+// Outage finds the locations affected if the given providers or services, referred to by provider name
+// or service label, were to go down. A location is affected if it uses one of the services, and it is
+// isolated if it can no longer reach any remaining service, either directly or through radio links.
+// The installed network devices of isolated locations are also given.
+func (t *Tree) Outage(names ...string) (ServiceImpacts, error) {
+	// duplicate service labels are left to validation, the first service offered is used
+	index, _ := NewServiceIndex(t.Providers)
+
+	down := make(map[string]bool)
+	for _, n := range names {
+		var found bool
+		for _, p := range t.Providers {
+			if p.Name != n {
+				continue
+			}
+			for _, s := range p.Services {
+				down[s.Name] = true
+			}
+			found = true
+		}
+		if _, ok := index.Lookup(n); ok {
+			down[n], found = true, true
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown provider or service: %s", n)
+		}
+	}
+
+	before := reachable(t.Locations, func(l Location) bool {
+		return len(l.Services) > 0
+	})
+	after := reachable(t.Locations, func(l Location) bool {
+		for _, s := range l.Services {
+			if !down[s] {
+				return true
+			}
+		}
+		return false
+	})
+
+	var impacts ServiceImpacts
+	for _, l := range t.Locations {
+		impact := ServiceImpact{
+			Location: l.Id,
+			Isolated: before[l.Id] && !after[l.Id],
+		}
+		for _, s := range l.Services {
+			if down[s] {
+				impact.Services = append(impact.Services, s)
+			}
+		}
+		if !impact.Isolated && len(impact.Services) == 0 {
+			continue
+		}
+		if n, ok := t.Network(l.Id); ok && impact.Isolated {
+			for _, d := range n.Devices {
+				if d.Uninstalled != nil && *d.Uninstalled {
+					continue
+				}
+				impact.Devices = append(impact.Devices, d.Name)
+			}
+		}
+		impacts = append(impacts, impact)
+	}
+
+	sort.Sort(impacts)
+
+	return impacts, nil
+}
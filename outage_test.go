@@ -0,0 +1,90 @@
+package metadata
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTree_Outage(t *testing.T) {
+
+	tree := &Tree{
+		Providers: []Provider{
+			{Name: "Provider 1", Services: []Service{{Name: "S1"}, {Name: "S2"}}},
+			{Name: "Provider 2", Services: []Service{{Name: "S3"}}},
+		},
+		Locations: []Location{
+			{Id: "A", Services: []string{"S1"}},
+			{Id: "B", Services: []string{"S2", "S3"}},
+			{Id: "C", Links: []Link{{Id: "A"}}},
+			{Id: "D", Links: []Link{{Id: "C"}}},
+			{Id: "E", Links: []Link{{Id: "B"}}},
+		},
+		Networks: []Network{
+			{Location: "C", Devices: []Device{
+				{Name: "rfc2a"},
+				{Name: "old", Uninstalled: &[]bool{true}[0]},
+			}},
+		},
+	}
+
+	summary := func(impacts ServiceImpacts) string {
+		var s []string
+		for _, i := range impacts {
+			s = append(s, fmt.Sprintf("%s%v%v%v", i.Location, i.Services, i.Isolated, i.Devices))
+		}
+		return fmt.Sprint(s)
+	}
+
+	t.Log("Check a provider outage.")
+	{
+		impacts, err := tree.Outage("Provider 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := summary(impacts); s != "[A[S1]true[] B[S2]false[] C[]true[rfc2a] D[]true[]]" {
+			t.Errorf("provider outage mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check a service outage.")
+	{
+		impacts, err := tree.Outage("S3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := summary(impacts); s != "[B[S3]false[]]" {
+			t.Errorf("service outage mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check a combined outage.")
+	{
+		impacts, err := tree.Outage("Provider 1", "S3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := summary(impacts); s != "[A[S1]true[] B[S2 S3]true[] C[]true[rfc2a] D[]true[] E[]true[]]" {
+			t.Errorf("combined outage mismatch: %s", s)
+		}
+	}
+
+	t.Log("Check an unknown outage.")
+	{
+		if _, err := tree.Outage("S4"); err == nil {
+			t.Error("expected an unknown service error")
+		}
+	}
+
+	t.Log("Check an outage with duplicate service labels.")
+	{
+		dup := *tree
+		dup.Providers = append(append([]Provider{}, tree.Providers...), Provider{Name: "Provider 3", Services: []Service{{Name: "S3"}}})
+		impacts, err := dup.Outage("Provider 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := summary(impacts); s != "[A[S1]true[] B[S2]false[] C[]true[rfc2a] D[]true[]]" {
+			t.Errorf("duplicate service outage mismatch: %s", s)
+		}
+	}
+}
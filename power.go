@@ -0,0 +1,148 @@
+package metadata
+
+import (
+	"sort"
+	"time"
+)
+
+// PowerBudget compares the expected power consumption of the equipment at a location with its supply capacity.
+type PowerBudget struct {
+	Location string   `json:"location"`
+	Load     float64  `json:"load"`
+	Unknown  []string `json:"unknown,omitempty"`
+	Capacity *Power   `json:"capacity,omitempty"`
+}
+
+// Consumption returns the expected daily energy use in watt hours.
+func (b PowerBudget) Consumption() float64 {
+	return b.Load * 24.0
+}
+
+// Generation returns the expected daily solar generation in watt hours.
+func (b PowerBudget) Generation() float64 {
+	if b.Capacity == nil || b.Capacity.Solar == nil || b.Capacity.Insolation == nil {
+		return 0.0
+	}
+	return *b.Capacity.Solar * *b.Capacity.Insolation
+}
+
+// Reserve returns the number of hours the battery can support the expected load.
+func (b PowerBudget) Reserve() float64 {
+	if b.Capacity == nil || b.Capacity.Battery == nil || !(b.Load > 0.0) {
+		return 0.0
+	}
+	return *b.Capacity.Battery / b.Load
+}
+
+// AtRisk returns whether a non-mains powered site is not expected to generate enough power,
+// or whether the battery is not expected to meet the required autonomy. Sites without a
+// capacity record are not considered to be at risk.
+func (b PowerBudget) AtRisk() bool {
+	switch {
+	case b.Capacity == nil:
+		return false
+	case b.Capacity.Mains != nil && *b.Capacity.Mains:
+		return false
+	case b.Generation() < b.Consumption():
+		return true
+	case b.Capacity.Autonomy != nil && b.Reserve() < *b.Capacity.Autonomy:
+		return true
+	default:
+		return false
+	}
+}
+
+type PowerBudgets []PowerBudget
+
+func (p PowerBudgets) Len() int           { return len(p) }
+func (p PowerBudgets) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p PowerBudgets) Less(i, j int) bool { return p[i].Location < p[j].Location }
+
+// AtRisk returns the budgets for sites which are at risk.
+func (p PowerBudgets) AtRisk() PowerBudgets {
+	var r PowerBudgets
+	for _, b := range p {
+		if b.AtRisk() {
+			r = append(r, b)
+		}
+	}
+	return r
+}
+
+// CalculatePowerBudgets estimates the power budget for each location using the equipment installed
+// at the given time, from any of the install lists, and any installed network devices. As equipment may
+// be recorded in both, the larger count of each model at a location is used. Models without a known power
+// draw are listed as unknown.
+func CalculatePowerBudgets(locations []Location, catalog *ModelCatalog, at time.Time, networks []Network, lists ...Installer) PowerBudgets {
+
+	counts := make(map[string]map[string]int)
+	for _, l := range locations {
+		counts[l.Id] = make(map[string]int)
+	}
+
+	for _, l := range lists {
+		for _, i := range l.Installations() {
+			if c, ok := counts[i.Location]; ok && i.Installed(at) {
+				c[i.Model]++
+			}
+		}
+	}
+
+	for _, n := range networks {
+		c, ok := counts[n.Location]
+		if !ok {
+			continue
+		}
+		devices := make(map[string]int)
+		for _, d := range n.Devices {
+			if d.Uninstalled != nil && *d.Uninstalled {
+				continue
+			}
+			devices[d.Model]++
+		}
+		for k, v := range devices {
+			if v > c[k] {
+				c[k] = v
+			}
+		}
+	}
+
+	var budgets PowerBudgets
+	for _, l := range locations {
+		b := PowerBudget{
+			Location: l.Id,
+			Capacity: l.Power,
+		}
+
+		var models Keys
+		for k := range counts[l.Id] {
+			models = append(models, k)
+		}
+		sort.Sort(models)
+
+		for _, m := range models {
+			if catalog == nil {
+				b.Unknown = append(b.Unknown, m)
+				continue
+			}
+			v, err := catalog.Resolve(m)
+			if err != nil || v.Power == nil {
+				b.Unknown = append(b.Unknown, m)
+				continue
+			}
+			b.Load += *v.Power * float64(counts[l.Id][m])
+		}
+
+		budgets = append(budgets, b)
+	}
+	sort.Sort(budgets)
+
+	return budgets
+}
+
+// PowerBudgets estimates the power budget of each location at the given time, using the installed radios,
+// equipment, sensors and dataloggers along with the network devices.
+func (t *Tree) PowerBudgets(at time.Time) PowerBudgets {
+	catalog, _ := NewModelCatalog(t.Models)
+	return CalculatePowerBudgets(t.Locations, catalog, at, t.Networks, t.Radios, t.Equipment, t.Sensors, t.Dataloggers)
+}
@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestPowerBudgets(t *testing.T) {
+
+	catalog, err := NewModelCatalog([]Model{
+		Model{
+			Name: "Example",
+			Versions: map[string]Version{
+				"model_1": Version{Name: "Model #1", Power: &[]float64{5.0}[0]},
+				"model_2": Version{Name: "Model #2", Power: &[]float64{2.0}[0]},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations := []Location{
+		Location{
+			Id: "Somewhere",
+			Power: &Power{
+				Solar:      &[]float64{100.0}[0],
+				Insolation: &[]float64{4.0}[0],
+				Battery:    &[]float64{1000.0}[0],
+				Autonomy:   &[]float64{120.0}[0],
+			},
+		},
+		Location{
+			Id: "Somewhere Else",
+		},
+	}
+
+	networks := []Network{
+		Network{
+			Location: "Somewhere",
+			Devices: []Device{
+				Device{Name: "a", Model: "Model #2"},
+				Device{Name: "b", Model: "Model #2"},
+				Device{Name: "c", Model: "Test Radio"},
+				Device{Name: "d", Model: "Model #1", Uninstalled: &[]bool{true}[0]},
+			},
+		},
+	}
+
+	t.Log("Check site power budgets.")
+	{
+		budgets := CalculatePowerBudgets(locations, catalog, MustParseTime("2010-06-01T00:00:00Z"), networks, testEquipmentInstalls)
+		if len(budgets) != 2 {
+			t.Fatalf("power budget count mismatch: %d != 2", len(budgets))
+		}
+
+		b := budgets[0]
+		if b.Location != "Somewhere" || b.Load != 9.0 {
+			t.Errorf("power budget load mismatch: %s %g", b.Location, b.Load)
+		}
+		if len(b.Unknown) != 1 || b.Unknown[0] != "Test Radio" {
+			t.Errorf("power budget unknown mismatch: %v", b.Unknown)
+		}
+		if b.Consumption() != 216.0 || b.Generation() != 400.0 {
+			t.Errorf("power budget energy mismatch: %g %g", b.Consumption(), b.Generation())
+		}
+		if !b.AtRisk() {
+			t.Error("power budget should be at risk with insufficient battery reserve")
+		}
+
+		if budgets[1].Load != 0.0 || budgets[1].AtRisk() {
+			t.Errorf("power budget mismatch: %v", budgets[1])
+		}
+
+		if r := budgets.AtRisk(); len(r) != 1 || r[0].Location != "Somewhere" {
+			t.Errorf("power budget risk mismatch: %v", r)
+		}
+	}
+
+	t.Log("Check site power budgets from several install lists.")
+	{
+		radios := RadioInstalls{
+			{Location: "Somewhere Else", Target: "Somewhere", Model: "Model #1", Serial: "Radio #1"},
+		}
+		budgets := CalculatePowerBudgets(locations, catalog, MustParseTime("2010-06-01T00:00:00Z"), networks, testEquipmentInstalls, radios)
+		if len(budgets) != 2 || budgets[0].Load != 9.0 || budgets[1].Load != 5.0 {
+			t.Errorf("power budget lists mismatch: %v", budgets)
+		}
+	}
+
+	t.Log("Check tree power budgets.")
+	{
+		tree := &Tree{
+			Locations: locations,
+			Models:    []Model{testModel},
+			Networks:  networks,
+			Equipment: testEquipmentInstalls,
+			Radios: RadioInstalls{
+				{Location: "Somewhere Else", Target: "Somewhere", Model: "Model #1", Serial: "Radio #1"},
+			},
+		}
+		budgets := tree.PowerBudgets(MustParseTime("2010-06-01T00:00:00Z"))
+		if len(budgets) != 2 || len(budgets[1].Unknown) != 1 || budgets[1].Unknown[0] != "Model #1" {
+			t.Errorf("tree power budgets mismatch: %v", budgets)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package metadata
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// StoreMode controls how existing files are treated when they are stored.
+type StoreMode int
+
+const (
+	// StoreCanonical replaces any existing file with the templated text.
+	StoreCanonical StoreMode = iota
+	// StorePreserve keeps any extra comments and unknown keys found in an existing file.
+	StorePreserve
+	// StoreStrict keeps any extra comments found in an existing file, but refuses to store over unknown keys.
+	StoreStrict
+)
+
+// Storable is implemented by the templated metadata types, e.g. Location, Network, Provider and Model.
+type Storable interface {
+	String() string
+}
+
+// DecodeStrict decodes a TOML file into the given value, returning an error if any keys are not known to it.
+func DecodeStrict(filename string, v interface{}) error {
+	md, err := toml.DecodeFile(filename, v)
+	if err != nil {
+		return err
+	}
+	if keys := undecoded(md); len(keys) > 0 {
+		return fmt.Errorf("%s: unknown keys: %s", filename, strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+func undecoded(md toml.MetaData) []string {
+	var keys []string
+	for _, k := range md.Undecoded() {
+		keys = append(keys, k.String())
+	}
+	return keys
+}
+
+// StoreTOML writes a templated value to the given path. Depending on the mode, any hand added comments or
+// unknown keys in an existing file are carried across into the new text. The returned warnings describe
+// any information in the existing file which could not be kept.
+func StoreTOML(path string, v Storable, mode StoreMode) ([]string, error) {
+
+	text := v.String()
+
+	var warnings []string
+	if original, err := ioutil.ReadFile(path); err == nil && mode != StoreCanonical {
+		// decode the existing file to find what would be regenerated from the template
+		previous := reflect.New(reflect.TypeOf(v))
+		md, err := toml.Decode(string(original), previous.Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		unknown := undecoded(md)
+		if mode == StoreStrict && len(unknown) > 0 {
+			return nil, fmt.Errorf("%s: unknown keys: %s", path, strings.Join(unknown, ", "))
+		}
+
+		stored, ok := previous.Elem().Interface().(Storable)
+		if !ok {
+			return nil, fmt.Errorf("%s: unable to store %T", path, v)
+		}
+
+		text, warnings = MergeTOML(string(original), stored.String(), text, unknown)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+// MergeTOML carries extra comments and unknown keys found in the original text across into the updated text.
+// The previous text is the templated version of the original, anything in the original which is not found
+// in it is considered to be extra. Extra lines are placed after the nearest preceding line in common with the
+// updated text. Warnings are returned for any extra lines which are not comments or known keys, and for any
+// unknown keys which could not be found.
+func MergeTOML(original, previous, updated string, unknown []string) (string, []string) {
+
+	orig := strings.Split(original, "\n")
+	prev := strings.Split(previous, "\n")
+	next := strings.Split(updated, "\n")
+
+	// how the original and the updated text map onto the previous text
+	origToPrev := matchLines(orig, prev)
+	prevToNext := matchLines(prev, next)
+
+	// previous lines which have been replaced are anchored to the end of their replacement
+	anchors := make([]int, len(prev))
+	for j, n := len(prev)-1, len(next)-1; j >= 0; j-- {
+		if m, ok := prevToNext[j]; ok {
+			anchors[j], n = m, m-1
+			continue
+		}
+		anchors[j] = n
+	}
+
+	unknowns := make(map[string]bool)
+	for _, k := range unknown {
+		unknowns[k] = false
+	}
+
+	var warnings []string
+
+	// extra lines to insert after a given updated line, -1 for the start
+	extra := make(map[int][]string)
+
+	var table string
+	var depth int
+	anchor := -1
+	for i, line := range orig {
+		trimmed := strings.TrimSpace(line)
+
+		// continuation of a multi-line unknown value
+		if depth > 0 {
+			depth += strings.Count(trimmed, "[") - strings.Count(trimmed, "]")
+			extra[anchor] = append(extra[anchor], line)
+			continue
+		}
+
+		if j, ok := origToPrev[i]; ok {
+			if strings.HasPrefix(trimmed, "[") {
+				table = tableName(trimmed)
+			}
+			anchor = anchors[j]
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			extra[anchor] = append(extra[anchor], line)
+		case strings.HasPrefix(trimmed, "["):
+			table = tableName(trimmed)
+			warnings = append(warnings, fmt.Sprintf("line %d: table %s may not be preserved", i+1, trimmed))
+		case strings.Contains(trimmed, "="):
+			key := strings.TrimSpace(strings.SplitN(trimmed, "=", 2)[0])
+			if table != "" {
+				key = table + "." + key
+			}
+			if _, ok := unknowns[key]; !ok {
+				// a known key which has been regenerated
+				continue
+			}
+			unknowns[key] = true
+			extra[anchor] = append(extra[anchor], line)
+
+			value := strings.SplitN(trimmed, "=", 2)[1]
+			if strings.Count(value, `"""`) == 1 || strings.Count(value, "'''") == 1 {
+				warnings = append(warnings, fmt.Sprintf("line %d: multi-line string %s may not be preserved", i+1, key))
+			}
+			depth = strings.Count(value, "[") - strings.Count(value, "]")
+		default:
+			warnings = append(warnings, fmt.Sprintf("line %d: unrecognised text will be lost: %s", i+1, trimmed))
+		}
+	}
+
+	for _, k := range unknown {
+		if !unknowns[k] {
+			warnings = append(warnings, fmt.Sprintf("unknown key %s will be lost", k))
+		}
+	}
+
+	var lines []string
+	lines = append(lines, extra[-1]...)
+	for n, line := range next {
+		lines = append(lines, line)
+		lines = append(lines, extra[n]...)
+	}
+
+	return strings.Join(lines, "\n"), warnings
+}
+
+func tableName(header string) string {
+	return strings.TrimSpace(strings.Trim(strings.SplitN(header, "#", 2)[0], "[] \t"))
+}
+
+// matchLines finds the longest common subsequence of lines, returning a map of matching indices.
+func matchLines(a, b []string) map[int]int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	matches := make(map[int]int)
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return matches
+}
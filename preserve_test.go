@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreTOML(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, LocationFile)
+
+	// a hand edited location file
+	edited := strings.Replace(testLocation.String(), `name = "A Location Name"`, `name = "A Location Name"
+# A hand added comment.
+height = 100.0
+extra = [
+    "a",
+    "b"
+]`, 1)
+	edited = strings.Replace(edited, `    id = "else"`, `    id = "else"
+    # Another hand added comment.
+    distance = 12.5`, 1)
+
+	if err := ioutil.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check strict loading of unknown keys.")
+	{
+		var l Location
+		if err := DecodeStrict(path, &l); err == nil {
+			t.Error("strict decoding should fail with unknown keys")
+		}
+		if err := DecodeStrict("testdata/location.toml", &l); err != nil {
+			t.Error(err)
+		}
+	}
+
+	t.Log("Check strict storing over unknown keys.")
+	{
+		if _, err := StoreTOML(path, testLocation, StoreStrict); err == nil {
+			t.Error("strict storing should fail with unknown keys")
+		}
+	}
+
+	t.Log("Check preserving unknown keys and comments.")
+	{
+		updated := testLocation
+		updated.Name = "A New Location Name"
+
+		warnings, err := StoreTOML(path, updated, StorePreserve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("unexpected store warnings: %v", warnings)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := strings.Replace(edited, `name = "A Location Name"`, `name = "A New Location Name"`, 1)
+		if string(b) != expected {
+			t.Errorf("preserved location mismatch: [\n%s\n]", SimpleDiff(string(b), expected))
+		}
+
+		var l Location
+		if err := DecodeStrict(path, &l); err == nil {
+			t.Error("preserved unknown keys should still be present")
+		}
+		if l.Name != updated.Name {
+			t.Errorf("preserved location name mismatch: %s", l.Name)
+		}
+	}
+
+	t.Log("Check canonical storing.")
+	{
+		if _, err := StoreTOML(path, testLocation, StoreCanonical); err != nil {
+			t.Fatal(err)
+		}
+		var l Location
+		if err := DecodeStrict(path, &l); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestMergeTOML(t *testing.T) {
+	t.Log("Check merge warnings.")
+	{
+		_, warnings := MergeTOML("a = 1\nb = 2\n!!!\n", "a = 1\n", "a = 1\n", []string{"b", "c"})
+		if len(warnings) != 2 || !strings.Contains(warnings[0], "unrecognised") || !strings.Contains(warnings[1], "unknown key c") {
+			t.Errorf("merge warnings mismatch: %v", warnings)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package metadata
 
 import (
-	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
-	"text/template"
-
-	"github.com/BurntSushi/toml"
+	"time"
 )
 
 const providerTemplate = `# IP4 network allocation tables, for a given service provider or entity.
@@ -20,11 +18,11 @@ const providerTemplate = `# IP4 network allocation tables, for a given service p
 #
 
 ## The name of the network provider.
-name = "{{.Name}}"
+name = "{{Escape .Name}}"
 
 ## Povider notes and documentation.
 {{if .Notes}}notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}    {{Escape $v}}\n\
 {{end}}    """{{else}}#notes = """\
 #    \n\
 #    """{{end}}
@@ -41,6 +39,25 @@ name = "{{.Name}}"
 #    ## Service contact details.
 #    #contact = ""
 #
+#    ## Provider circuit identifier.
+#    #circuit = ""
+#
+#    ## Contract start and end times.
+#    #start = 2006-01-02T00:00:00Z
+#    #end = 2006-01-02T00:00:00Z
+#
+#    ## Contract renewal notice period in days.
+#    #renewal_notice = days
+#
+#    ## Monthly service cost.
+#    #monthly_cost = cost
+#
+#    ## Service bandwidth in Mbps.
+#    #bandwidth = Mbps
+#
+#    ## Service level agreement.
+#    #sla = ""
+#
 #    ## Service specific notes.
 #    #notes = """\
 #    #    \n\
@@ -48,17 +65,36 @@ name = "{{.Name}}"
 
 [[service]]
     ## The provided service.
-    name = "{{.Name}}"
+    name = "{{Escape .Name}}"
 
     ## Service reference.
-{{if .Reference}}    reference = "{{.Reference}}"{{else}}    #reference = ""{{end}}
+{{if .Reference}}    reference = "{{Escape .Reference}}"{{else}}    #reference = ""{{end}}
 
     ## Service contact details.
-{{if .Contact}}    contact = "{{.Contact}}"{{else}}    #contact = ""{{end}}
+{{if .Contact}}    contact = "{{Escape .Contact}}"{{else}}    #contact = ""{{end}}
+
+    ## Provider circuit identifier.
+{{if .Circuit}}    circuit = "{{Escape .Circuit}}"{{else}}    #circuit = ""{{end}}
+
+    ## Contract start and end times.
+{{if .Start}}    start = {{DateTimePtr .Start}}{{else}}    #start = 2006-01-02T00:00:00Z{{end}}
+{{if .End}}    end = {{DateTimePtr .End}}{{else}}    #end = 2006-01-02T00:00:00Z{{end}}
+
+    ## Contract renewal notice period in days.
+{{if .RenewalNotice}}    renewal_notice = {{.RenewalNotice}}{{else}}    #renewal_notice = days{{end}}
+
+    ## Monthly service cost.
+{{if .MonthlyCost}}    monthly_cost = {{Float .MonthlyCost}}{{else}}    #monthly_cost = cost{{end}}
+
+    ## Service bandwidth in Mbps.
+{{if .Bandwidth}}    bandwidth = {{Float .Bandwidth}}{{else}}    #bandwidth = Mbps{{end}}
+
+    ## Service level agreement.
+{{if .SLA}}    sla = "{{Escape .SLA}}"{{else}}    #sla = ""{{end}}
 
     ## Service specific notes.
 {{if .Notes}}    notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{Escape $v}}\n\
 {{end}}        """{{else}}    #notes = """\
     #    \n\
     #    """{{end}}{{end}}
@@ -82,10 +118,10 @@ name = "{{.Name}}"
 
 [[range]]
     ## The name of the network range.
-    name = "{{.Name}}"
+    name = "{{Escape .Name}}"
 
     ## The network area identification.
-    area = "{{.Area}}"
+    area = "{{Escape .Area}}"
 
     ## An array of networks.
 {{if .Networks}}    networks = [{{range $n, $t := .Networks}}{{if gt $n 0}},{{end}}
@@ -94,7 +130,7 @@ name = "{{.Name}}"
 
     ## Optional model specific notes and documentation.
 {{if .Notes}}    notes = """\
-{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{$v}}\n\
+{{$lines := Lines .Notes}}{{range $k, $v := $lines}}        {{Escape $v}}\n\
 {{end}}        """{{else}}    #notes = """\
     #    \n\
     #    """{{end}}{{end}}
@@ -103,10 +139,17 @@ name = "{{.Name}}"
 `
 
 type Service struct {
-	Name      string  `json:"name"`
-	Reference *string `json:"reference"`
-	Contact   *string `json:"contact"`
-	Notes     *string `json:"notes"`
+	Name          string     `json:"name"`
+	Reference     *string    `json:"reference"`
+	Contact       *string    `json:"contact"`
+	Circuit       *string    `json:"circuit,omitempty"`
+	Start         *time.Time `json:"start,omitempty"`
+	End           *time.Time `json:"end,omitempty"`
+	RenewalNotice *int       `json:"renewal_notice,omitempty" toml:"renewal_notice"`
+	MonthlyCost   *float64   `json:"monthly_cost,omitempty" toml:"monthly_cost"`
+	Bandwidth     *float64   `json:"bandwidth,omitempty"`
+	SLA           *string    `json:"sla,omitempty" toml:"sla"`
+	Notes         *string    `json:"notes"`
 }
 
 type Range struct {
@@ -126,7 +169,7 @@ type Provider struct {
 func LoadProvider(filename string) (*Provider, error) {
 	var p Provider
 
-	if _, err := toml.DecodeFile(filename, &p); err != nil {
+	if err := DecodeFile(filename, &p); err != nil {
 		return nil, err
 	}
 
@@ -137,7 +180,7 @@ func LoadProviders(dirname, filename string) ([]Provider, error) {
 	var pp []Provider
 
 	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
-		if err == nil && filepath.Base(path) == filename {
+		if err == nil && metadataName(filepath.Base(path)) == filename {
 			p, e := LoadProvider(path)
 			if e != nil {
 				return e
@@ -154,40 +197,43 @@ func LoadProviders(dirname, filename string) ([]Provider, error) {
 }
 
 func (pro Provider) StoreProvider(path string) error {
+	return StoreFile(path, pro)
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	_, err = file.Write([]byte(pro.String()))
-	if err != nil {
-		return err
-	}
+func (pro Provider) String() string {
+	return mustExecute(ProviderTemplate, pro)
+}
 
-	return nil
+// ProviderService is a service along with the name of the provider offering it.
+type ProviderService struct {
+	Provider string `json:"provider"`
+	Service
 }
 
-func (pro Provider) String() string {
-	tplFuncMap := make(template.FuncMap)
-	tplFuncMap["Lines"] = Lines
+// ServiceIndex indexes provider services by their labels.
+type ServiceIndex map[string]ProviderService
 
-	tmpl, err := template.New("").Funcs(tplFuncMap).Parse(providerTemplate)
-	if err != nil {
-		panic(err)
-	}
+// NewServiceIndex builds an index of the services offered by the providers, service labels are expected to be
+// globally unique. Any duplicate labels are reported, with the first service offered being kept in the index.
+func NewServiceIndex(providers []Provider) (ServiceIndex, []error) {
+	var errs []error
 
-	var doc bytes.Buffer
-	err = tmpl.Execute(&doc, pro)
-	if err != nil {
-		panic(err)
+	index := make(ServiceIndex)
+	for _, p := range providers {
+		for _, s := range p.Services {
+			if o, ok := index[s.Name]; ok {
+				errs = append(errs, fmt.Errorf("provider %s: duplicate service %s, also offered by %s", p.Name, s.Name, o.Provider))
+				continue
+			}
+			index[s.Name] = ProviderService{Provider: p.Name, Service: s}
+		}
 	}
 
-	return doc.String()
+	return index, errs
+}
 
+// Lookup returns the provider service with the given label.
+func (s ServiceIndex) Lookup(label string) (ProviderService, bool) {
+	v, ok := s[label]
+	return v, ok
 }
@@ -3,6 +3,7 @@ package metadata
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -15,10 +16,17 @@ func init() {
 		Name:  "Example Provider",
 		Notes: &[]string{"Some Notes\nSome More Notes"}[0],
 		Services: []Service{Service{
-			Name:      "Test Service",
-			Notes:     &[]string{"Some Notes\nSome More Notes"}[0],
-			Reference: &[]string{"ABC1234"}[0],
-			Contact:   &[]string{"0800 123123"}[0],
+			Name:          "Test Service",
+			Notes:         &[]string{"Some Notes\nSome More Notes"}[0],
+			Reference:     &[]string{"ABC1234"}[0],
+			Contact:       &[]string{"0800 123123"}[0],
+			Circuit:       &[]string{"CCT-0001"}[0],
+			Start:         &[]time.Time{MustParseTime("2015-07-01T00:00:00Z")}[0],
+			End:           &[]time.Time{MustParseTime("2018-06-30T00:00:00Z")}[0],
+			RenewalNotice: &[]int{90}[0],
+			MonthlyCost:   &[]float64{450.5}[0],
+			Bandwidth:     &[]float64{100}[0],
+			SLA:           &[]string{"99.9% availability, 4 hour restore"}[0],
 		}},
 		Ranges: []Range{
 			Range{
@@ -103,3 +111,35 @@ func TestProvider_LoadFiles(t *testing.T) {
 		//t.Log(p[0].String())
 	}
 }
+
+func TestProvider_ServiceIndex(t *testing.T) {
+
+	t.Log("Check provider service lookups.")
+	{
+		index, errs := NewServiceIndex([]Provider{testProvider})
+		if len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		if s, ok := index.Lookup("Test Service"); !ok || s.Provider != "Example Provider" || *s.Reference != "ABC1234" {
+			t.Error("provider service lookup mismatch")
+		}
+		if _, ok := index.Lookup("Test 1 Service"); ok {
+			t.Error("unexpected provider service")
+		}
+	}
+
+	t.Log("Check duplicate provider services.")
+	{
+		other := Provider{Name: "Other Provider", Services: append([]Service{{Name: "Other Service"}}, testProvider.Services...)}
+		index, errs := NewServiceIndex([]Provider{testProvider, other, other})
+		if len(errs) != len(testProvider.Services)+len(other.Services) {
+			t.Errorf("expected all duplicate provider services to be reported: %v", errs)
+		}
+		if s, ok := index.Lookup("Test Service"); !ok || s.Provider != testProvider.Name {
+			t.Error("duplicate provider services should keep the first service")
+		}
+		if _, ok := index.Lookup("Other Service"); !ok {
+			t.Error("missing provider service after duplicates")
+		}
+	}
+}
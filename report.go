@@ -0,0 +1,253 @@
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"time"
+)
+
+const siteReportTemplate = `# {{.Location.Id}}: {{.Location.Name}}
+
+Report generated {{DateTime .Generated}}.
+
+## Location
+
+| | |
+|---|---|
+| Latitude | {{with .Location.Latitude}}{{LatLon .}}{{else}}-{{end}} |
+| Longitude | {{with .Location.Longitude}}{{LatLon .}}{{else}}-{{end}} |
+| Datum | {{Cell (Default "-" .Location.Datum)}} |
+| Elevation | {{Fixed 1 .Location.Elevation}} |
+{{- with .Grid}}
+| NZTM | {{printf "%.0f" .Easting}} E {{printf "%.0f" .Northing}} N |{{end}}
+| Tags | {{Cell (Join .Location.Tags ", ")}} |
+
+## Access
+{{with .Location.SiteAccess}}
+| | |
+|---|---|
+| Gate | {{Cell (Default "-" .Gate)}} |
+| Keys | {{Cell (Join .Keys ", ")}} |
+| Landowner | {{Cell (Default "-" .Landowner)}} |
+| Contact | {{Cell (Default "-" .Contact)}} |
+| Hazards | {{Cell (Join .Hazards "; ")}} |
+| Restrictions | {{Cell (Join .Restrictions "; ")}} |
+{{end}}{{with .Location.Access}}
+{{range Lines .}}{{.}}
+{{end}}{{end}}
+## Services
+
+| Service | Provider | Reference | Contact |
+|---|---|---|---|
+{{range .Services}}| {{Cell .Name}} | {{Cell .Provider}} | {{Cell (Default "-" .Reference)}} | {{Cell (Default "-" .Contact)}} |
+{{end}}
+## Network
+{{with .Network}}
+Runnet: {{with .Runnet}}{{.}}{{else}}-{{end}}
+
+| Device | Model | Address | Aliases |
+|---|---|---|---|
+{{range .Devices}}| {{Cell .Name}} | {{Cell .Model}} | {{with .Address}}{{.}}{{else}}-{{end}} | {{range $n, $a := .Aliases}}{{if gt $n 0}}, {{end}}{{$a}}{{else}}-{{end}} |
+{{end}}{{else}}
+No network has been recorded.
+{{end}}
+## Radio Links
+
+| Target | Role | Key | Polarity | Distance (km) | Bearing | Frequency (MHz) |
+|---|---|---|---|---|---|---|
+{{range .Links}}| {{Cell .Target}} | {{Cell (Default "-" .Role)}} | {{Cell (Default "-" .Key)}} | {{Cell (Default "-" .Polarity)}} | {{Fixed 2 .Distance}} | {{Fixed 1 .Bearing}} | {{Fixed 3 .Frequency}} |
+{{end}}
+## Installed Equipment
+
+| Model | Serial | Asset | Firmware | Installed |
+|---|---|---|---|---|
+{{range .Equipment}}| {{Cell .Model}} | {{Cell .Serial}} | {{Cell (Default "-" .Asset)}} | {{Cell (Default "-" .Firmware)}} | {{if .Start.IsZero}}-{{else}}{{DateTime .Start}}{{end}} |
+{{end}}`
+
+const siteReportHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Location.Id}}: {{.Location.Name}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #999; padding: 0.2em 0.5em; text-align: left; }
+@media print { h2 { page-break-after: avoid; } }
+</style>
+</head>
+<body>
+<h1>{{.Location.Id}}: {{.Location.Name}}</h1>
+<p>Report generated {{DateTime .Generated}}.</p>
+
+<h2>Location</h2>
+<table>
+<tr><th>Latitude</th><td>{{with .Location.Latitude}}{{LatLon .}}{{else}}-{{end}}</td></tr>
+<tr><th>Longitude</th><td>{{with .Location.Longitude}}{{LatLon .}}{{else}}-{{end}}</td></tr>
+<tr><th>Datum</th><td>{{Default "-" .Location.Datum}}</td></tr>
+<tr><th>Elevation</th><td>{{Fixed 1 .Location.Elevation}}</td></tr>
+{{- with .Grid}}
+<tr><th>NZTM</th><td>{{printf "%.0f" .Easting}} E {{printf "%.0f" .Northing}} N</td></tr>{{end}}
+<tr><th>Tags</th><td>{{Join .Location.Tags ", "}}</td></tr>
+</table>
+
+<h2>Access</h2>
+{{- with .Location.SiteAccess}}
+<table>
+<tr><th>Gate</th><td>{{Default "-" .Gate}}</td></tr>
+<tr><th>Keys</th><td>{{Join .Keys ", "}}</td></tr>
+<tr><th>Landowner</th><td>{{Default "-" .Landowner}}</td></tr>
+<tr><th>Contact</th><td>{{Default "-" .Contact}}</td></tr>
+<tr><th>Hazards</th><td>{{Join .Hazards "; "}}</td></tr>
+<tr><th>Restrictions</th><td>{{Join .Restrictions "; "}}</td></tr>
+</table>{{end}}
+{{- with .Location.Access}}
+<p>{{range $n, $l := Lines .}}{{if gt $n 0}}<br>{{end}}{{$l}}{{end}}</p>{{end}}
+
+<h2>Services</h2>
+<table>
+<tr><th>Service</th><th>Provider</th><th>Reference</th><th>Contact</th></tr>
+{{- range .Services}}
+<tr><td>{{.Name}}</td><td>{{.Provider}}</td><td>{{Default "-" .Reference}}</td><td>{{Default "-" .Contact}}</td></tr>{{end}}
+</table>
+
+<h2>Network</h2>
+{{- with .Network}}
+<p>Runnet: {{with .Runnet}}{{.String}}{{else}}-{{end}}</p>
+<table>
+<tr><th>Device</th><th>Model</th><th>Address</th><th>Aliases</th></tr>
+{{- range .Devices}}
+<tr><td>{{.Name}}</td><td>{{.Model}}</td><td>{{with .Address}}{{.String}}{{else}}-{{end}}</td><td>{{range $n, $a := .Aliases}}{{if gt $n 0}}, {{end}}{{$a.String}}{{else}}-{{end}}</td></tr>{{end}}
+</table>{{else}}
+<p>No network has been recorded.</p>{{end}}
+
+<h2>Radio Links</h2>
+<table>
+<tr><th>Target</th><th>Role</th><th>Key</th><th>Polarity</th><th>Distance (km)</th><th>Bearing</th><th>Frequency (MHz)</th></tr>
+{{- range .Links}}
+<tr><td>{{.Target}}</td><td>{{Default "-" .Role}}</td><td>{{Default "-" .Key}}</td><td>{{Default "-" .Polarity}}</td><td>{{Fixed 2 .Distance}}</td><td>{{Fixed 1 .Bearing}}</td><td>{{Fixed 3 .Frequency}}</td></tr>{{end}}
+</table>
+
+<h2>Installed Equipment</h2>
+<table>
+<tr><th>Model</th><th>Serial</th><th>Asset</th><th>Firmware</th><th>Installed</th></tr>
+{{- range .Equipment}}
+<tr><td>{{.Model}}</td><td>{{.Serial}}</td><td>{{Default "-" .Asset}}</td><td>{{Default "-" .Firmware}}</td><td>{{if .Start.IsZero}}-{{else}}{{DateTime .Start}}{{end}}</td></tr>{{end}}
+</table>
+</body>
+</html>
+`
+
+// ReportService is a service used by a location, along with its provider details if known.
+type ReportService struct {
+	Name      string  `json:"name"`
+	Provider  string  `json:"provider"`
+	Reference *string `json:"reference,omitempty"`
+	Contact   *string `json:"contact,omitempty"`
+}
+
+// ReportEquipment is an installation along with its asset number and current firmware, if known. Radios are
+// included but have no installation start time.
+type ReportEquipment struct {
+	Installation
+	Asset    *string `json:"asset,omitempty"`
+	Firmware *string `json:"firmware,omitempty"`
+}
+
+// SiteReport gathers the metadata relevant to a single location.
+type SiteReport struct {
+	Generated time.Time         `json:"generated"`
+	Location  Location          `json:"location"`
+	Grid      *GridPoint        `json:"grid,omitempty"`
+	Services  []ReportService   `json:"services,omitempty"`
+	Network   *Network          `json:"network,omitempty"`
+	Links     LinkBudgets       `json:"links,omitempty"`
+	Equipment []ReportEquipment `json:"equipment,omitempty"`
+}
+
+// SiteReport collects the details, access, services, network, radio links and equipment installed at the
+// given time for a location.
+func (t *Tree) SiteReport(id string, at time.Time) (*SiteReport, error) {
+	l, ok := t.Location(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown location: %s", id)
+	}
+
+	report := SiteReport{
+		Generated: at,
+		Location:  *l,
+	}
+	if g, ok := l.NZTM(); ok {
+		report.Grid = &g
+	}
+
+	// duplicate service labels are left to validation, the first service offered is reported
+	index, _ := NewServiceIndex(t.Providers)
+	for _, s := range l.Services {
+		r := ReportService{Name: s, Provider: "-"}
+		if v, ok := index.Lookup(s); ok {
+			r.Provider, r.Reference, r.Contact = v.Provider, v.Reference, v.Contact
+		}
+		report.Services = append(report.Services, r)
+	}
+
+	if n, ok := t.Network(l.Id); ok {
+		network := *n
+		network.Devices = nil
+		for _, d := range n.Devices {
+			if d.Uninstalled != nil && *d.Uninstalled {
+				continue
+			}
+			network.Devices = append(network.Devices, d)
+		}
+		report.Network = &network
+	}
+
+	var frequency LinkFrequency
+	if keys, err := NewFrequencyKeys(t.Frequencies); err == nil {
+		frequency = keys.LinkFrequency
+	}
+	catalog, _ := NewModelCatalog(t.Models)
+	for _, b := range CalculateLinkBudgets(t.Locations, catalog, t.Radios, t.Networks, frequency) {
+		if b.Location == l.Id {
+			report.Links = append(report.Links, b)
+		}
+	}
+
+	assets := make(map[[2]string]string)
+	for _, a := range t.Assets {
+		assets[[2]string{a.Model, a.Serial}] = a.Asset
+	}
+	for _, i := range t.Installed(at) {
+		if i.Location != l.Id {
+			continue
+		}
+		e := ReportEquipment{Installation: i}
+		if a, ok := assets[[2]string{i.Model, i.Serial}]; ok {
+			e.Asset = &a
+		}
+		if f, ok := t.Firmware.Firmware(i.Model, i.Serial, at); ok {
+			e.Firmware = &f.Version
+		}
+		report.Equipment = append(report.Equipment, e)
+	}
+
+	return &report, nil
+}
+
+// Markdown renders the site report as Markdown.
+func (r SiteReport) Markdown() (string, error) {
+	return Render(SiteReportTemplate, r)
+}
+
+var siteReportHTML = htmltemplate.Must(htmltemplate.New("report").Funcs(htmltemplate.FuncMap(TemplateFuncs())).Parse(siteReportHTMLTemplate))
+
+// HTML renders the site report as a printable HTML page, all values are escaped.
+func (r SiteReport) HTML() (string, error) {
+	var doc bytes.Buffer
+	if err := siteReportHTML.Execute(&doc, r); err != nil {
+		return "", err
+	}
+	return doc.String(), nil
+}
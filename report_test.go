@@ -0,0 +1,137 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func testReportTree() *Tree {
+	network := testNetwork
+	network.Location = testLocation.Id
+
+	return &Tree{
+		Locations: []Location{
+			testLocation,
+			{Id: "somewhere", Name: "Somewhere", Latitude: &[]float64{-41.4}[0], Longitude: &[]float64{174.2}[0]},
+		},
+		Networks: []Network{network},
+		Providers: []Provider{{
+			Name: "Example | Provider",
+			Services: []Service{{
+				Name:      "Test 1 Service",
+				Reference: &[]string{"ABC1234"}[0],
+				Contact:   &[]string{"0800 123123"}[0],
+			}},
+		}},
+		Frequencies: []FrequencyPlan{{
+			Frequencies: []Frequency{{Key: "Key 1", Centre: &[]float64{5800.0}[0]}},
+		}},
+		Assets: AssetList{
+			{Model: "Model A", Serial: "Serial #1", Asset: "Asset #1"},
+		},
+		Equipment: EquipmentInstalls{
+			{Location: "location", Model: "Model A", Serial: "Serial #1", Start: MustParseTime("2010-01-01T00:00:00Z"), Stop: MustParseTime("9999-01-01T00:00:00Z")},
+			{Location: "location", Model: "Model B", Serial: "Serial #2", Start: MustParseTime("2010-01-01T00:00:00Z"), Stop: MustParseTime("2011-01-01T00:00:00Z")},
+			{Location: "somewhere", Model: "Model B", Serial: "Serial #3", Start: MustParseTime("2010-01-01T00:00:00Z"), Stop: MustParseTime("9999-01-01T00:00:00Z")},
+		},
+		Radios: RadioInstalls{
+			{Location: "location", Target: "else", Model: "Radio Model", Serial: "Radio #1"},
+		},
+		Firmware: testFirmwareInstalls,
+	}
+}
+
+func TestTree_SiteReport(t *testing.T) {
+
+	tree := testReportTree()
+	at := MustParseTime("2016-01-02T00:00:00Z")
+
+	report, err := tree.SiteReport("location", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check site report contents.")
+	{
+		if len(report.Services) != 2 || report.Services[0].Provider != "Example | Provider" || report.Services[1].Provider != "-" {
+			t.Errorf("report services mismatch: %v", report.Services)
+		}
+		if report.Network == nil || len(report.Network.Devices) != 1 || report.Network.Devices[0].Name != "test1-network" {
+			t.Error("report network devices mismatch")
+		}
+		if len(report.Links) != 2 || report.Links[0].Target != "else" || report.Links[1].Frequency == nil {
+			t.Error("report links mismatch")
+		}
+		if len(report.Equipment) != 2 || report.Equipment[1].Model != "Radio Model" || report.Equipment[1].Asset != nil {
+			t.Errorf("report radio equipment mismatch: %v", report.Equipment)
+		}
+		if report.Equipment[0].Asset == nil || report.Equipment[0].Firmware == nil || *report.Equipment[0].Firmware != "1.2.0" {
+			t.Error("report equipment mismatch")
+		}
+	}
+
+	t.Log("Check rendering a markdown site report.")
+	{
+		b, err := ioutil.ReadFile("testdata/report.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := report.Markdown()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != string(b) {
+			t.Errorf("markdown report mismatch: [\n%s\n]", SimpleDiff(s, string(b)))
+		}
+	}
+
+	t.Log("Check rendering a html site report.")
+	{
+		b, err := ioutil.ReadFile("testdata/report.html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := report.HTML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != string(b) {
+			t.Errorf("html report mismatch: [\n%s\n]", SimpleDiff(s, string(b)))
+		}
+	}
+
+	t.Log("Check reporting services with duplicate providers.")
+	{
+		dup := *tree
+		dup.Providers = append(append([]Provider{}, tree.Providers...), Provider{Name: "Other", Services: tree.Providers[0].Services})
+		r, err := dup.SiteReport("location", at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Services) != 2 || r.Services[0].Provider != "Example | Provider" {
+			t.Errorf("duplicate report services mismatch: %v", r.Services)
+		}
+	}
+
+	t.Log("Check reporting an unknown location.")
+	{
+		if _, err := tree.SiteReport("unknown", at); err == nil {
+			t.Error("expected an unknown location error")
+		}
+	}
+
+	t.Log("Check reporting a bare location.")
+	{
+		r, err := (&Tree{Locations: []Location{{Id: "bare", Name: "<Bare>"}}}).SiteReport("bare", at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s, err := r.Markdown(); err != nil || !strings.Contains(s, "No network has been recorded.") {
+			t.Errorf("bare markdown report mismatch: [\n%s\n]", s)
+		}
+		if s, err := r.HTML(); err != nil || !strings.Contains(s, "&lt;Bare&gt;") || strings.Contains(s, "<Bare>") {
+			t.Errorf("bare html report mismatch: [\n%s\n]", s)
+		}
+	}
+}
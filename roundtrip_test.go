@@ -0,0 +1,284 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runes which are likely to cause problems when written into TOML strings.
+var testRunes = []rune("abcXYZ019 _-.,:;#=[]{}\"'\\\t\r\b\f\x01\x1f\x7féü°日本語😀")
+
+func randomText(r *rand.Rand) string {
+	n := r.Intn(12)
+	rr := make([]rune, n)
+	for i := range rr {
+		rr[i] = testRunes[r.Intn(len(testRunes))]
+	}
+	return string(rr)
+}
+
+func randomString(r *rand.Rand) *string {
+	if r.Intn(4) == 0 {
+		return nil
+	}
+	s := randomText(r)
+	return &s
+}
+
+func randomStrings(r *rand.Rand) []string {
+	var ss []string
+	for i, n := 0, r.Intn(4); i < n; i++ {
+		ss = append(ss, randomText(r))
+	}
+	return ss
+}
+
+// randomFloat builds values across the formatting paths of Float, including negative and whole numbers
+// and very large or very small magnitudes.
+func randomFloat(r *rand.Rand) *float64 {
+	var f float64
+	switch r.Intn(6) {
+	case 0:
+		return nil
+	case 1:
+		f = float64(r.Intn(20001) - 10000)
+	case 2:
+		f = r.NormFloat64() * 1000.0
+	case 3:
+		f = math.Ldexp(r.Float64(), r.Intn(2000)-1000)
+	case 4:
+		f = math.Pow10(r.Intn(600) - 300)
+	default:
+		f = float64(r.Int63()) * 1000.0
+	}
+	if r.Intn(2) > 0 {
+		f = -f
+	}
+	return &f
+}
+
+// randomNotes builds multi-line text, the template indentation means lines can't start or end with spaces.
+func randomNotes(r *rand.Rand) *string {
+	if r.Intn(4) == 0 {
+		return nil
+	}
+	var lines []string
+	for i, n := 0, r.Intn(4)+1; i < n; i++ {
+		lines = append(lines, strings.Trim(randomText(r), " "))
+	}
+	s := strings.Join(lines, "\n")
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return &s
+}
+
+// roundTrip stores and reloads a value, checking the stored text is unchanged, the loaded value is returned.
+func roundTrip(t *testing.T, v Storable, load func(string) (Storable, error)) Storable {
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "roundtrip.toml")
+	if err := ioutil.WriteFile(path, []byte(v.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := load(path)
+	if err != nil {
+		t.Fatalf("unable to load stored text: %v [\n%s\n]", err, v.String())
+	}
+	if l.String() != v.String() {
+		t.Fatalf("round trip mismatch: [\n%s\n]", SimpleDiff(l.String(), v.String()))
+	}
+
+	return l
+}
+
+func TestRoundTrip_Location(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	t.Log("Check location round trips.")
+	for i := 0; i < 200; i++ {
+		loc := Location{
+			Id:       randomText(r),
+			Name:     randomText(r),
+			Datum:    randomString(r),
+			Services: randomStrings(r),
+			Tags:     randomStrings(r),
+			Access:   randomNotes(r),
+			Notes:    randomNotes(r),
+		}
+		if r.Intn(2) > 0 {
+			loc.SiteAccess = &SiteAccess{
+				Gate:         randomString(r),
+				Keys:         randomStrings(r),
+				Landowner:    randomString(r),
+				Contact:      randomString(r),
+				Hazards:      randomStrings(r),
+				Restrictions: randomStrings(r),
+			}
+			if r.Intn(2) > 0 {
+				loc.SiteAccess.Helicopter = &[]bool{r.Intn(2) > 0}[0]
+			}
+			if r.Intn(2) > 0 {
+				loc.SiteAccess.FourWheelDrive = &[]bool{r.Intn(2) > 0}[0]
+			}
+		}
+		if r.Intn(2) > 0 {
+			loc.Power = &Power{
+				Solar:      randomFloat(r),
+				Insolation: randomFloat(r),
+				Battery:    randomFloat(r),
+				Autonomy:   randomFloat(r),
+			}
+			if r.Intn(2) > 0 {
+				loc.Power.Mains = &[]bool{r.Intn(2) > 0}[0]
+			}
+		}
+		loc.Ground = randomFloat(r)
+		if r.Intn(4) > 0 {
+			lat, lon := r.Float64()*180.0-90.0, r.Float64()*360.0-180.0
+			loc.Latitude, loc.Longitude = &lat, &lon
+		}
+		if r.Intn(4) > 0 {
+			elevation := math.Round(r.NormFloat64()*1000.0) / 10.0
+			loc.Elevation = &elevation
+		}
+		for j, n := 0, r.Intn(3); j < n; j++ {
+			loc.Links = append(loc.Links, Link{
+				Id:       randomText(r),
+				Role:     randomString(r),
+				Key:      randomString(r),
+				Polarity: randomString(r),
+			})
+		}
+		l := roundTrip(t, loc, func(path string) (Storable, error) { return LoadLocation(path) }).(*Location)
+		if !reflect.DeepEqual(l.Ground, loc.Ground) || !reflect.DeepEqual(l.Power, loc.Power) || !reflect.DeepEqual(l.SiteAccess, loc.SiteAccess) {
+			t.Fatalf("round trip value mismatch: [\n%s\n]", loc.String())
+		}
+	}
+}
+
+func TestRoundTrip_Network(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	t.Log("Check network round trips.")
+	for i := 0; i < 200; i++ {
+		n := Network{
+			Location: randomText(r),
+			Name:     randomString(r),
+			Notes:    randomNotes(r),
+			Runnet:   MustParseIPNetwork("192.168.1.0/28"),
+		}
+		for j, k := 0, r.Intn(3); j < k; j++ {
+			n.Linknets = append(n.Linknets, Linknet{Name: randomText(r)})
+		}
+		for j, k := 0, r.Intn(3); j < k; j++ {
+			n.Devices = append(n.Devices, Device{
+				Name:    randomText(r),
+				Model:   randomText(r),
+				Address: MustParseIPAddress("192.168.1.1/28"),
+				Tags:    randomStrings(r),
+				Links:   randomStrings(r),
+				Notes:   randomNotes(r),
+			})
+		}
+		roundTrip(t, n, func(path string) (Storable, error) { return LoadNetwork(path) })
+	}
+}
+
+func TestRoundTrip_Provider(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	t.Log("Check provider round trips.")
+	for i := 0; i < 200; i++ {
+		p := Provider{
+			Name:  randomText(r),
+			Notes: randomNotes(r),
+		}
+		for j, k := 0, r.Intn(3); j < k; j++ {
+			s := Service{
+				Name:      randomText(r),
+				Reference: randomString(r),
+				Contact:   randomString(r),
+				Circuit:   randomString(r),
+				SLA:       randomString(r),
+				Notes:     randomNotes(r),
+			}
+			if r.Intn(2) > 0 {
+				start := time.Unix(r.Int63n(2e9), 0).UTC()
+				end := start.AddDate(r.Intn(5)+1, 0, 0)
+				notice := r.Intn(180) + 1
+				s.Start, s.End, s.RenewalNotice = &start, &end, &notice
+			}
+			if r.Intn(2) > 0 {
+				cost, bandwidth := math.Round(r.Float64()*100000.0)/100.0, float64(r.Intn(1000)+1)
+				s.MonthlyCost, s.Bandwidth = &cost, &bandwidth
+			}
+			p.Services = append(p.Services, s)
+		}
+		for j, k := 0, r.Intn(3); j < k; j++ {
+			p.Ranges = append(p.Ranges, Range{
+				Name:     randomText(r),
+				Area:     randomText(r),
+				Networks: []IPNetwork{*MustParseIPNetwork("10.0.0.0/8")},
+				Notes:    randomNotes(r),
+			})
+		}
+		roundTrip(t, p, func(path string) (Storable, error) { return LoadProvider(path) })
+	}
+}
+
+func TestRoundTrip_Model(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	t.Log("Check model round trips.")
+	for i := 0; i < 200; i++ {
+		m := Model{
+			Name:         randomText(r),
+			Manufacturer: randomText(r),
+			Notes:        randomNotes(r),
+			Versions:     make(map[string]Version),
+		}
+		if m.Notes == nil {
+			m.Notes = &[]string{"Notes"}[0]
+		}
+		for j, k := 0, r.Intn(3); j < k; j++ {
+			m.Versions[randomText(r)] = Version{
+				Name:        randomText(r),
+				Type:        randomText(r),
+				Tags:        randomStrings(r),
+				Firmware:    randomStrings(r),
+				Recommended: randomString(r),
+				Notes:       randomNotes(r),
+				Power:       randomFloat(r),
+				MinVoltage:  randomFloat(r),
+				MaxVoltage:  randomFloat(r),
+				Mass:        randomFloat(r),
+				Gain:        randomFloat(r),
+				Transmit:    randomFloat(r),
+				Sensitivity: randomFloat(r),
+			}
+		}
+		l := roundTrip(t, m, func(path string) (Storable, error) { return LoadModel(path) }).(*Model)
+		for k, v := range m.Versions {
+			w := l.Versions[k]
+			for _, f := range [][2]*float64{{v.Power, w.Power}, {v.MinVoltage, w.MinVoltage}, {v.MaxVoltage, w.MaxVoltage},
+				{v.Mass, w.Mass}, {v.Gain, w.Gain}, {v.Transmit, w.Transmit}, {v.Sensitivity, w.Sensitivity}} {
+				if !reflect.DeepEqual(f[0], f[1]) {
+					t.Fatalf("round trip value mismatch: [\n%s\n]", m.String())
+				}
+			}
+		}
+	}
+}
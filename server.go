@@ -0,0 +1,232 @@
+package metadata
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// LocatedDevice is a network device along with its network location.
+type LocatedDevice struct {
+	Location string `json:"location"`
+	Device
+}
+
+// Server is a read-only http.Handler providing a JSON view of a metadata tree.
+//
+// The available endpoints are:
+//
+//	/locations[?tag=]
+//	/locations/<id>
+//	/networks
+//	/networks/<location>
+//	/devices[?location=&model=&tag=]
+//	/providers
+//	/providers/<name>
+//	/models[?type=&tag=]
+//	/installs[?at=&location=&model=]
+//
+// Responses carry an ETag, and requests with a matching If-None-Match header, including "*" or a list
+// of tags, are answered with 304 Not Modified.
+type Server struct {
+	watcher *Watcher
+}
+
+// NewServer returns a Server providing the current snapshot of the given Watcher, the watcher is expected
+// to be run separately to pick up any changes.
+func NewServer(watcher *Watcher) *Server {
+	return &Server{
+		watcher: watcher,
+	}
+}
+
+// Tree returns the current metadata tree snapshot.
+func (s *Server) Tree() *Tree {
+	return s.watcher.Tree()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	tree := s.Tree()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	var name string
+	if len(parts) > 1 {
+		name = parts[1]
+	}
+
+	query := r.URL.Query()
+
+	var v interface{}
+	switch parts[0] {
+	case "locations":
+		if name != "" {
+			l, ok := tree.Location(name)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			v = l
+			break
+		}
+		locations := []Location{}
+		for _, l := range tree.Locations {
+			if tag := query.Get("tag"); tag != "" && !hasString(l.Tags, tag) {
+				continue
+			}
+			locations = append(locations, l)
+		}
+		v = locations
+	case "networks":
+		if name != "" {
+			n, ok := tree.Network(name)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			v = n
+			break
+		}
+		v = append([]Network{}, tree.Networks...)
+	case "devices":
+		if name != "" {
+			http.NotFound(w, r)
+			return
+		}
+		devices := []LocatedDevice{}
+		for _, n := range tree.Networks {
+			if loc := query.Get("location"); loc != "" && n.Location != loc {
+				continue
+			}
+			for _, d := range n.Devices {
+				if model := query.Get("model"); model != "" && d.Model != model {
+					continue
+				}
+				if tag := query.Get("tag"); tag != "" && !hasString(d.Tags, tag) {
+					continue
+				}
+				devices = append(devices, LocatedDevice{Location: n.Location, Device: d})
+			}
+		}
+		v = devices
+	case "providers":
+		if name != "" {
+			var found *Provider
+			for i := range tree.Providers {
+				if tree.Providers[i].Name == name {
+					found = &tree.Providers[i]
+					break
+				}
+			}
+			if found == nil {
+				http.NotFound(w, r)
+				return
+			}
+			v = found
+			break
+		}
+		v = append([]Provider{}, tree.Providers...)
+	case "models":
+		if name != "" {
+			http.NotFound(w, r)
+			return
+		}
+		catalog, err := NewModelCatalog(tree.Models)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		versions := catalog.Filter(func(m ModelVersion) bool {
+			if t := query.Get("type"); t != "" && m.Type != t {
+				return false
+			}
+			if tag := query.Get("tag"); tag != "" && !m.HasTag(tag) {
+				return false
+			}
+			return true
+		})
+		v = append(ModelVersions{}, versions...)
+	case "installs":
+		if name != "" {
+			http.NotFound(w, r)
+			return
+		}
+		installs := tree.Installations()
+		if at := query.Get("at"); at != "" {
+			t, err := ParseTime(at)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid time: %s", at), http.StatusBadRequest)
+				return
+			}
+			installs = tree.Installed(t)
+		}
+		list := Installations{}
+		for _, i := range installs {
+			if loc := query.Get("location"); loc != "" && i.Location != loc {
+				continue
+			}
+			if model := query.Get("model"); model != "" && i.Model != model {
+				continue
+			}
+			list = append(list, i)
+		}
+		v = list
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := fmt.Sprintf("\"%x\"", sha1.Sum(body.Bytes()))
+
+	w.Header().Set("ETag", etag)
+	if matchETag(r.Header["If-None-Match"], etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(body.Bytes())
+}
+
+// matchETag returns whether any of the If-None-Match header values match the entity tag, the values may
+// be "*" or comma separated lists of strong or weak tags, which are compared weakly.
+func matchETag(headers []string, etag string) bool {
+	for _, h := range headers {
+		for _, m := range strings.Split(h, ",") {
+			switch m = strings.TrimSpace(m); {
+			case m == "*":
+				return true
+			case strings.TrimPrefix(m, "W/") == etag:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+
+	w, err := NewWatcher("testdata", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(w)
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Log("Check server location endpoints.")
+	{
+		w := get("/locations/location", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("server status mismatch: %d", w.Code)
+		}
+		var l Location
+		if err := json.Unmarshal(w.Body.Bytes(), &l); err != nil {
+			t.Fatal(err)
+		}
+		if l.String() != testLocation.String() {
+			t.Errorf("server location mismatch: [\n%s\n]", SimpleDiff(l.String(), testLocation.String()))
+		}
+
+		var ll []Location
+		if err := json.Unmarshal(get("/locations?tag=XYZ", "").Body.Bytes(), &ll); err != nil || len(ll) != 0 {
+			t.Errorf("server location filter mismatch: %v", ll)
+		}
+		if w := get("/locations/unknown", ""); w.Code != http.StatusNotFound {
+			t.Errorf("server status mismatch: %d", w.Code)
+		}
+	}
+
+	t.Log("Check server filtered endpoints.")
+	{
+		var devices []LocatedDevice
+		if err := json.Unmarshal(get("/devices?tag=EFG", "").Body.Bytes(), &devices); err != nil {
+			t.Fatal(err)
+		}
+		if len(devices) != 1 || devices[0].Name != "test1-network" || devices[0].Location != "network" {
+			t.Errorf("server device filter mismatch: %v", devices)
+		}
+
+		var versions ModelVersions
+		if err := json.Unmarshal(get("/models?type=Model+Type+A", "").Body.Bytes(), &versions); err != nil {
+			t.Fatal(err)
+		}
+		if len(versions) != 1 || versions[0].Name != "Model A" {
+			t.Errorf("server model filter mismatch: %v", versions)
+		}
+
+		var installs Installations
+		if err := json.Unmarshal(get("/installs?at=2012-06-01T00:00:00Z&location=Somewhere+Else", "").Body.Bytes(), &installs); err != nil {
+			t.Fatal(err)
+		}
+		if len(installs) != 3 || installs[2].Model != "Radio Model #1" {
+			t.Errorf("server installs filter mismatch: %v", installs)
+		}
+		if w := get("/installs?at=yesterday", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("server status mismatch: %d", w.Code)
+		}
+	}
+
+	t.Log("Check server etag support.")
+	{
+		w := get("/providers", "")
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("server missing etag")
+		}
+		for _, match := range []string{etag, "*", "\"other\", " + etag, "W/" + etag, "\"a\",W/" + etag + " , \"b\""} {
+			if w := get("/providers", match); w.Code != http.StatusNotModified {
+				t.Errorf("server status mismatch for %s: %d", match, w.Code)
+			}
+		}
+		for _, match := range []string{"\"other\"", "\"a\", \"b\"", etag[1:]} {
+			if w := get("/providers", match); w.Code != http.StatusOK {
+				t.Errorf("server status mismatch for %s: %d", match, w.Code)
+			}
+		}
+	}
+}
+
+func TestServer_DuplicateProviders(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, n := range []string{"first", "second"} {
+		p := Provider{Name: "Example Provider", Notes: &[]string{n}[0]}
+		if err := p.StoreProvider(filepath.Join(dir, n, ProviderFile)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewWatcher(dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check server providers with duplicate names.")
+	{
+		r := httptest.NewRequest("GET", "/providers/Example%20Provider", nil)
+		rec := httptest.NewRecorder()
+		NewServer(w).ServeHTTP(rec, r)
+
+		var p Provider
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.TrimSpace(Default("", p.Notes)); n != "first" {
+			t.Errorf("server should return the first provider: %s", n)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+package metadata
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SQLiteDriver is the database/sql driver name used by ExportSQLite, the pure Go modernc.org/sqlite
+// driver registers itself under this name and needs to be imported by the calling program.
+var SQLiteDriver = "sqlite"
+
+// sqliteSchema holds the table definitions used for exporting a metadata tree, child tables reference
+// their parent entities by name. The foreign keys document these relationships, SQLite only enforces them
+// when the foreign_keys pragma has been turned on, which the export leaves off.
+var sqliteSchema = []string{
+	`CREATE TABLE locations (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		latitude REAL,
+		longitude REAL,
+		datum TEXT,
+		elevation REAL,
+		ground REAL,
+		access TEXT,
+		gate TEXT,
+		landowner TEXT,
+		contact TEXT,
+		four_wheel_drive INTEGER,
+		helicopter INTEGER,
+		mains INTEGER,
+		solar REAL,
+		insolation REAL,
+		battery REAL,
+		autonomy REAL,
+		notes TEXT
+	)`,
+	`CREATE TABLE location_services (
+		location TEXT NOT NULL REFERENCES locations(id),
+		service TEXT NOT NULL,
+		PRIMARY KEY (location, service)
+	)`,
+	`CREATE TABLE location_tags (
+		location TEXT NOT NULL REFERENCES locations(id),
+		tag TEXT NOT NULL,
+		PRIMARY KEY (location, tag)
+	)`,
+	`CREATE TABLE location_access (
+		location TEXT NOT NULL REFERENCES locations(id),
+		kind TEXT NOT NULL,
+		value TEXT NOT NULL
+	)`,
+	`CREATE INDEX location_access_location ON location_access(location)`,
+	`CREATE TABLE links (
+		location TEXT NOT NULL REFERENCES locations(id),
+		target TEXT NOT NULL,
+		role TEXT,
+		key TEXT,
+		polarity TEXT
+	)`,
+	`CREATE INDEX links_location ON links(location)`,
+	`CREATE INDEX links_target ON links(target)`,
+
+	`CREATE TABLE providers (
+		name TEXT PRIMARY KEY,
+		notes TEXT
+	)`,
+	`CREATE TABLE services (
+		provider TEXT NOT NULL REFERENCES providers(name),
+		name TEXT NOT NULL,
+		reference TEXT,
+		contact TEXT,
+		circuit TEXT,
+		contract_start TEXT,
+		contract_end TEXT,
+		renewal_notice INTEGER,
+		monthly_cost REAL,
+		bandwidth REAL,
+		sla TEXT,
+		notes TEXT,
+		PRIMARY KEY (provider, name)
+	)`,
+	`CREATE INDEX services_name ON services(name)`,
+	`CREATE TABLE ranges (
+		provider TEXT NOT NULL REFERENCES providers(name),
+		name TEXT NOT NULL,
+		area TEXT NOT NULL,
+		notes TEXT,
+		PRIMARY KEY (provider, name)
+	)`,
+	`CREATE TABLE range_networks (
+		provider TEXT NOT NULL,
+		name TEXT NOT NULL,
+		network TEXT NOT NULL,
+		FOREIGN KEY (provider, name) REFERENCES ranges(provider, name)
+	)`,
+	`CREATE INDEX range_networks_range ON range_networks(provider, name)`,
+
+	`CREATE TABLE networks (
+		location TEXT PRIMARY KEY REFERENCES locations(id),
+		name TEXT,
+		runnet TEXT,
+		notes TEXT
+	)`,
+	`CREATE TABLE linknets (
+		location TEXT NOT NULL REFERENCES networks(location),
+		name TEXT NOT NULL
+	)`,
+	`CREATE INDEX linknets_location ON linknets(location)`,
+	`CREATE TABLE devices (
+		location TEXT NOT NULL REFERENCES networks(location),
+		name TEXT NOT NULL,
+		model TEXT NOT NULL,
+		address TEXT,
+		uninstalled INTEGER,
+		notes TEXT,
+		PRIMARY KEY (location, name)
+	)`,
+	`CREATE INDEX devices_model ON devices(model)`,
+	`CREATE INDEX devices_address ON devices(address)`,
+	`CREATE TABLE device_aliases (
+		location TEXT NOT NULL,
+		device TEXT NOT NULL,
+		address TEXT NOT NULL,
+		FOREIGN KEY (location, device) REFERENCES devices(location, name)
+	)`,
+	`CREATE INDEX device_aliases_device ON device_aliases(location, device)`,
+	`CREATE TABLE device_tags (
+		location TEXT NOT NULL,
+		device TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		FOREIGN KEY (location, device) REFERENCES devices(location, name)
+	)`,
+	`CREATE INDEX device_tags_device ON device_tags(location, device)`,
+	`CREATE TABLE device_links (
+		location TEXT NOT NULL,
+		device TEXT NOT NULL,
+		link TEXT NOT NULL,
+		FOREIGN KEY (location, device) REFERENCES devices(location, name)
+	)`,
+	`CREATE INDEX device_links_device ON device_links(location, device)`,
+
+	`CREATE TABLE models (
+		name TEXT PRIMARY KEY,
+		manufacturer TEXT NOT NULL,
+		notes TEXT
+	)`,
+	`CREATE TABLE versions (
+		model TEXT NOT NULL REFERENCES models(name),
+		id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		power REAL,
+		min_voltage REAL,
+		max_voltage REAL,
+		mass REAL,
+		gain REAL,
+		transmit REAL,
+		sensitivity REAL,
+		recommended TEXT,
+		notes TEXT,
+		PRIMARY KEY (model, id)
+	)`,
+	`CREATE INDEX versions_name ON versions(name)`,
+	`CREATE INDEX versions_type ON versions(type)`,
+	`CREATE TABLE version_attributes (
+		model TEXT NOT NULL,
+		version TEXT NOT NULL,
+		attribute TEXT NOT NULL,
+		value TEXT NOT NULL,
+		FOREIGN KEY (model, version) REFERENCES versions(model, id)
+	)`,
+	`CREATE INDEX version_attributes_version ON version_attributes(model, version)`,
+	`CREATE INDEX version_attributes_value ON version_attributes(attribute, value)`,
+
+	`CREATE TABLE frequencies (
+		key TEXT PRIMARY KEY,
+		centre REAL,
+		bandwidth REAL,
+		licence TEXT,
+		notes TEXT
+	)`,
+
+	`CREATE TABLE assets (
+		model TEXT NOT NULL,
+		serial TEXT NOT NULL,
+		asset TEXT NOT NULL
+	)`,
+	`CREATE INDEX assets_serial ON assets(model, serial)`,
+	`CREATE TABLE radios (
+		location TEXT NOT NULL,
+		target TEXT NOT NULL,
+		role TEXT NOT NULL,
+		model TEXT NOT NULL,
+		serial TEXT NOT NULL,
+		polarity TEXT NOT NULL,
+		frequency REAL NOT NULL
+	)`,
+	`CREATE INDEX radios_location ON radios(location)`,
+	`CREATE INDEX radios_serial ON radios(model, serial)`,
+	`CREATE TABLE equipment (
+		location TEXT NOT NULL,
+		model TEXT NOT NULL,
+		serial TEXT NOT NULL,
+		start TEXT NOT NULL,
+		stop TEXT NOT NULL
+	)`,
+	`CREATE INDEX equipment_location ON equipment(location)`,
+	`CREATE INDEX equipment_serial ON equipment(model, serial)`,
+	`CREATE TABLE sensors (
+		station TEXT NOT NULL,
+		site TEXT NOT NULL,
+		model TEXT NOT NULL,
+		serial TEXT NOT NULL,
+		azimuth REAL NOT NULL,
+		dip REAL NOT NULL,
+		depth REAL NOT NULL,
+		start TEXT NOT NULL,
+		stop TEXT NOT NULL
+	)`,
+	`CREATE INDEX sensors_station ON sensors(station)`,
+	`CREATE INDEX sensors_serial ON sensors(model, serial)`,
+	`CREATE TABLE dataloggers (
+		station TEXT NOT NULL,
+		site TEXT NOT NULL,
+		model TEXT NOT NULL,
+		serial TEXT NOT NULL,
+		start TEXT NOT NULL,
+		stop TEXT NOT NULL
+	)`,
+	`CREATE INDEX dataloggers_station ON dataloggers(station)`,
+	`CREATE INDEX dataloggers_serial ON dataloggers(model, serial)`,
+	`CREATE TABLE firmware (
+		model TEXT NOT NULL,
+		serial TEXT NOT NULL,
+		version TEXT NOT NULL,
+		config TEXT,
+		start TEXT NOT NULL
+	)`,
+	`CREATE INDEX firmware_serial ON firmware(model, serial)`,
+}
+
+// ExportSQLite writes the metadata tree into a new SQLite database at the given path, any existing
+// database is only replaced once the export has succeeded. The SQLiteDriver needs to have been
+// registered by the calling program.
+func ExportSQLite(path string, tree *Tree) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+	defer os.Remove(tmp)
+
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := exportSQLite(tmp, tree); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func exportSQLite(path string, tree *Tree) error {
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := ExportSQL(db, tree); err != nil {
+		return err
+	}
+
+	return db.Close()
+}
+
+// ExportSQL creates the metadata tables in an empty database and fills them from the metadata tree,
+// all within a single transaction.
+func ExportSQL(db *sql.DB, tree *Tree) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := exportSQL(tx, tree); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func exportSQL(tx *sql.Tx, tree *Tree) error {
+	for _, s := range sqliteSchema {
+		if _, err := tx.Exec(s); err != nil {
+			return err
+		}
+	}
+
+	var inserts [][]interface{}
+	insert := func(table string, values ...interface{}) {
+		inserts = append(inserts, append([]interface{}{table}, values...))
+	}
+
+	for _, l := range tree.Locations {
+		var mains interface{}
+		var solar, insolation, battery, autonomy *float64
+		if p := l.Power; p != nil {
+			if p.Mains != nil {
+				mains = *p.Mains
+			}
+			solar, insolation, battery, autonomy = p.Solar, p.Insolation, p.Battery, p.Autonomy
+		}
+		var gate, landowner, contact, fourWheelDrive, helicopter interface{}
+		if a := l.SiteAccess; a != nil {
+			gate, landowner, contact = sqlString(a.Gate), sqlString(a.Landowner), sqlString(a.Contact)
+			if a.FourWheelDrive != nil {
+				fourWheelDrive = *a.FourWheelDrive
+			}
+			if a.Helicopter != nil {
+				helicopter = *a.Helicopter
+			}
+			for _, v := range []struct {
+				kind   string
+				values []string
+			}{
+				{"key", a.Keys},
+				{"hazard", a.Hazards},
+				{"restriction", a.Restrictions},
+			} {
+				for _, s := range v.values {
+					insert("location_access", l.Id, v.kind, s)
+				}
+			}
+		}
+		insert("locations", l.Id, l.Name, sqlFloat(l.Latitude), sqlFloat(l.Longitude),
+			sqlString(l.Datum), sqlFloat(l.Elevation), sqlFloat(l.Ground), sqlString(l.Access),
+			gate, landowner, contact, fourWheelDrive, helicopter, mains, sqlFloat(solar), sqlFloat(insolation), sqlFloat(battery), sqlFloat(autonomy), sqlString(l.Notes))
+		for _, s := range l.Services {
+			insert("location_services", l.Id, s)
+		}
+		for _, t := range l.Tags {
+			insert("location_tags", l.Id, t)
+		}
+		for _, k := range l.Links {
+			insert("links", l.Id, k.Id, sqlString(k.Role), sqlString(k.Key), sqlString(k.Polarity))
+		}
+	}
+
+	for _, p := range tree.Providers {
+		insert("providers", p.Name, sqlString(p.Notes))
+		for _, s := range p.Services {
+			var start, end, notice interface{}
+			if s.Start != nil {
+				start = sqlTime(*s.Start)
+			}
+			if s.End != nil {
+				end = sqlTime(*s.End)
+			}
+			if s.RenewalNotice != nil {
+				notice = int64(*s.RenewalNotice)
+			}
+			insert("services", p.Name, s.Name, sqlString(s.Reference), sqlString(s.Contact), sqlString(s.Circuit),
+				start, end, notice, sqlFloat(s.MonthlyCost), sqlFloat(s.Bandwidth), sqlString(s.SLA), sqlString(s.Notes))
+		}
+		for _, r := range p.Ranges {
+			insert("ranges", p.Name, r.Name, r.Area, sqlString(r.Notes))
+			for _, n := range r.Networks {
+				insert("range_networks", p.Name, r.Name, n.String())
+			}
+		}
+	}
+
+	for _, n := range tree.Networks {
+		var runnet interface{}
+		if n.Runnet != nil {
+			runnet = n.Runnet.String()
+		}
+		insert("networks", n.Location, sqlString(n.Name), runnet, sqlString(n.Notes))
+		for _, l := range n.Linknets {
+			insert("linknets", n.Location, l.Name)
+		}
+		for _, d := range n.Devices {
+			var address, uninstalled interface{}
+			if d.Address != nil {
+				address = d.Address.String()
+			}
+			if d.Uninstalled != nil {
+				uninstalled = *d.Uninstalled
+			}
+			insert("devices", n.Location, d.Name, d.Model, address, uninstalled, sqlString(d.Notes))
+			for _, a := range d.Aliases {
+				insert("device_aliases", n.Location, d.Name, a.String())
+			}
+			for _, t := range d.Tags {
+				insert("device_tags", n.Location, d.Name, t)
+			}
+			for _, l := range d.Links {
+				insert("device_links", n.Location, d.Name, l)
+			}
+		}
+	}
+
+	for _, m := range tree.Models {
+		insert("models", m.Name, m.Manufacturer, sqlString(m.Notes))
+		for k, v := range m.Versions {
+			insert("versions", m.Name, k, v.Name, v.Type, sqlFloat(v.Power), sqlFloat(v.MinVoltage),
+				sqlFloat(v.MaxVoltage), sqlFloat(v.Mass), sqlFloat(v.Gain), sqlFloat(v.Transmit), sqlFloat(v.Sensitivity),
+				sqlString(v.Recommended), sqlString(v.Notes))
+			for _, a := range []struct {
+				attribute string
+				values    []string
+			}{
+				{"tag", v.Tags},
+				{"port", v.Ports},
+				{"firmware", v.Firmware},
+				{"datasheet", v.Datasheets},
+			} {
+				for _, s := range a.values {
+					insert("version_attributes", m.Name, k, a.attribute, s)
+				}
+			}
+		}
+	}
+
+	for _, p := range tree.Frequencies {
+		for _, f := range p.Frequencies {
+			insert("frequencies", f.Key, sqlFloat(f.Centre), sqlFloat(f.Bandwidth), sqlString(f.Licence), sqlString(f.Notes))
+		}
+	}
+
+	for _, a := range tree.Assets {
+		insert("assets", a.Model, a.Serial, a.Asset)
+	}
+	for _, r := range tree.Radios {
+		insert("radios", r.Location, r.Target, r.Role, r.Model, r.Serial, r.Polarity, r.Frequency)
+	}
+	for _, e := range tree.Equipment {
+		insert("equipment", e.Location, e.Model, e.Serial, sqlTime(e.Start), sqlTime(e.Stop))
+	}
+	for _, s := range tree.Sensors {
+		insert("sensors", s.Station, s.Site, s.Model, s.Serial, s.Azimuth, s.Dip, s.Depth, sqlTime(s.Start), sqlTime(s.Stop))
+	}
+	for _, d := range tree.Dataloggers {
+		insert("dataloggers", d.Station, d.Site, d.Model, d.Serial, sqlTime(d.Start), sqlTime(d.Stop))
+	}
+	for _, f := range tree.Firmware {
+		insert("firmware", f.Model, f.Serial, f.Version, f.Config, sqlTime(f.Start))
+	}
+
+	stmts := make(map[string]*sql.Stmt)
+	for _, r := range inserts {
+		table := r[0].(string)
+		stmt, ok := stmts[table]
+		if !ok {
+			params := strings.TrimSuffix(strings.Repeat("?, ", len(r)-1), ", ")
+			s, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", table, params))
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+			stmt, stmts[table] = s, s
+		}
+		if _, err := stmt.Exec(r[1:]...); err != nil {
+			return fmt.Errorf("%s: %v", table, err)
+		}
+	}
+
+	return nil
+}
+
+func sqlString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func sqlFloat(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func sqlTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
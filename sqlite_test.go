@@ -0,0 +1,185 @@
+package metadata
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLite_ExportSQLite(t *testing.T) {
+
+	tree, err := LoadTree("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "metadata.db")
+
+	count := func(table string) int {
+		db, err := sql.Open(SQLiteDriver, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	t.Log("Check exporting a sqlite database.")
+	{
+		if err := ExportSQLite(path, tree); err != nil {
+			t.Fatal(err)
+		}
+		counts := map[string]int{
+			"locations":   len(tree.Locations),
+			"links":       len(tree.Locations[0].Links),
+			"providers":   len(tree.Providers),
+			"services":    len(tree.Providers[0].Services),
+			"networks":    len(tree.Networks),
+			"devices":     len(tree.Networks[0].Devices),
+			"models":      len(tree.Models),
+			"versions":    len(tree.Models[0].Versions),
+			"assets":      len(tree.Assets),
+			"radios":      len(tree.Radios),
+			"equipment":   len(tree.Equipment),
+			"sensors":     len(tree.Sensors),
+			"dataloggers": len(tree.Dataloggers),
+			"firmware":    len(tree.Firmware),
+		}
+		for k, v := range counts {
+			if n := count(k); n != v {
+				t.Errorf("%s rows mismatch: %d != %d", k, n, v)
+			}
+		}
+	}
+
+	t.Log("Check exported sqlite values.")
+	{
+		db, err := sql.Open(SQLiteDriver, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		var id, name, start string
+		var latitude float64
+		if err := db.QueryRow("SELECT id, name, latitude FROM locations").Scan(&id, &name, &latitude); err != nil {
+			t.Fatal(err)
+		}
+		if l := tree.Locations[0]; id != l.Id || name != l.Name || latitude != *l.Latitude {
+			t.Errorf("location row mismatch: %s %s %g", id, name, latitude)
+		}
+		if err := db.QueryRow("SELECT start FROM equipment ORDER BY start LIMIT 1").Scan(&start); err != nil {
+			t.Fatal(err)
+		}
+		if start != "2010-01-01T00:00:00Z" {
+			t.Errorf("equipment start mismatch: %s", start)
+		}
+	}
+
+	t.Log("Check exported sqlite foreign keys.")
+	{
+		db, err := sql.Open(SQLiteDriver, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		references := map[string]string{
+			"location_services":  "location>locations.id",
+			"location_tags":      "location>locations.id",
+			"location_access":    "location>locations.id",
+			"links":              "location>locations.id",
+			"services":           "provider>providers.name",
+			"ranges":             "provider>providers.name",
+			"range_networks":     "provider>ranges.provider name>ranges.name",
+			"networks":           "location>locations.id",
+			"linknets":           "location>networks.location",
+			"devices":            "location>networks.location",
+			"device_aliases":     "location>devices.location device>devices.name",
+			"device_tags":        "location>devices.location device>devices.name",
+			"device_links":       "location>devices.location device>devices.name",
+			"versions":           "model>models.name",
+			"version_attributes": "model>versions.model version>versions.id",
+		}
+		for table, expected := range references {
+			rows, err := db.Query("PRAGMA foreign_key_list(" + table + ")")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cols, err := rows.Columns()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for rows.Next() {
+				values := make([]interface{}, len(cols))
+				fields := make(map[string]*sql.NullString)
+				for i, c := range cols {
+					fields[c] = &sql.NullString{}
+					values[i] = fields[c]
+				}
+				if err := rows.Scan(values...); err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, fields["from"].String+">"+fields["table"].String+"."+fields["to"].String)
+			}
+			rows.Close()
+			if s := strings.Join(keys, " "); s != expected {
+				t.Errorf("%s foreign keys mismatch: \"%s\" != \"%s\"", table, s, expected)
+			}
+		}
+	}
+
+	t.Log("Check exporting a tree with dangling references.")
+	{
+		dangling := filepath.Join(dir, "dangling.db")
+		if err := ExportSQLite(dangling, &Tree{Networks: []Network{{Location: "unknown"}}}); err != nil {
+			t.Errorf("foreign keys should not be enforced: %v", err)
+		}
+		os.Remove(dangling)
+	}
+
+	t.Log("Check replacing an existing sqlite database.")
+	{
+		if err := ExportSQLite(path, &Tree{Locations: tree.Locations}); err != nil {
+			t.Fatal(err)
+		}
+		if n := count("providers"); n != 0 {
+			t.Errorf("replaced database should have no providers: %d", n)
+		}
+	}
+
+	t.Log("Check failed exports keep the existing sqlite database.")
+	{
+		bad := &Tree{Locations: []Location{{Id: "A", Services: []string{"S", "S"}}}}
+		if err := ExportSQLite(path, bad); err == nil {
+			t.Error("expected a duplicate location service error")
+		}
+		if n := count("locations"); n != len(tree.Locations) {
+			t.Errorf("existing database should be kept: %d locations", n)
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Errorf("temporary files should be removed: %d files", len(files))
+		}
+	}
+}
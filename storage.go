@@ -0,0 +1,122 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Supported storage formats, chosen by file extension.
+const (
+	TOMLFormat = ".toml"
+	JSONFormat = ".json"
+	YAMLFormat = ".yaml"
+)
+
+// fileFormat returns the storage format for the given file name, files without a known extension are TOML.
+func fileFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return JSONFormat
+	case ".yaml", ".yml":
+		return YAMLFormat
+	default:
+		return TOMLFormat
+	}
+}
+
+// DecodeFile decodes a TOML, JSON or YAML file, based on its extension, into the given value.
+// JSON and YAML files use the json struct tags.
+func DecodeFile(filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return DecodeFormat(data, fileFormat(filename), v)
+}
+
+// DecodeFormat decodes TOML, JSON or YAML text into the given value.
+func DecodeFormat(data []byte, format string, v interface{}) error {
+	switch format {
+	case JSONFormat:
+		return json.Unmarshal(data, v)
+	case YAMLFormat:
+		var y interface{}
+		if err := yaml.Unmarshal(data, &y); err != nil {
+			return err
+		}
+		b, err := json.Marshal(yamlToJSON(y))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	default:
+		_, err := toml.Decode(string(data), v)
+		return err
+	}
+}
+
+// EncodeFormat renders a templated value in the given format, TOML uses the value's template.
+func EncodeFormat(v Storable, format string) ([]byte, error) {
+	switch format {
+	case JSONFormat:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case YAMLFormat:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		// JSON is valid YAML, decoding into a MapSlice keeps the field order.
+		var m yaml.MapSlice
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(m)
+	default:
+		return []byte(v.String()), nil
+	}
+}
+
+// StoreFile writes a templated value as TOML, JSON or YAML depending on the file extension.
+func StoreFile(path string, v Storable) error {
+
+	data, err := EncodeFormat(v, fileFormat(path))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// yamlToJSON converts decoded YAML maps, which may have non string keys, into JSON compatible maps.
+func yamlToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for k, e := range v {
+			m[fmt.Sprintf("%v", k)] = yamlToJSON(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = yamlToJSON(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
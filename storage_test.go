@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStorage_Formats(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, ext := range []string{".toml", ".json", ".yaml", ".yml"} {
+		t.Logf("Check storing and loading %s files.", ext)
+		{
+			if err := testLocation.StoreLocation(filepath.Join(dir, "a", "location"+ext)); err != nil {
+				t.Fatal(err)
+			}
+			l, err := LoadLocation(filepath.Join(dir, "a", "location"+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l.String() != testLocation.String() {
+				t.Errorf("location %s mismatch: [\n%s\n]", ext, SimpleDiff(l.String(), testLocation.String()))
+			}
+
+			if err := testNetwork.StoreNetwork(filepath.Join(dir, "b", "network"+ext)); err != nil {
+				t.Fatal(err)
+			}
+			n, err := LoadNetwork(filepath.Join(dir, "b", "network"+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.String() != testNetwork.String() {
+				t.Errorf("network %s mismatch: [\n%s\n]", ext, SimpleDiff(n.String(), testNetwork.String()))
+			}
+
+			if err := testProvider.StoreProvider(filepath.Join(dir, "c", "provider"+ext)); err != nil {
+				t.Fatal(err)
+			}
+			p, err := LoadProvider(filepath.Join(dir, "c", "provider"+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.String() != testProvider.String() {
+				t.Errorf("provider %s mismatch: [\n%s\n]", ext, SimpleDiff(p.String(), testProvider.String()))
+			}
+
+			if err := testModel.StoreModel(filepath.Join(dir, "d", "model"+ext)); err != nil {
+				t.Fatal(err)
+			}
+			m, err := LoadModel(filepath.Join(dir, "d", "model"+ext))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.String() != testModel.String() {
+				t.Errorf("model %s mismatch: [\n%s\n]", ext, SimpleDiff(m.String(), testModel.String()))
+			}
+
+			os.RemoveAll(filepath.Join(dir, "a"))
+		}
+	}
+
+	t.Log("Check loading a tree of mixed formats.")
+	{
+		if err := testLocation.StoreLocation(filepath.Join(dir, "a", "location.json")); err != nil {
+			t.Fatal(err)
+		}
+		tree, err := LoadTree(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tree.Locations) != 1 || len(tree.Networks) != 4 || len(tree.Providers) != 4 || len(tree.Models) != 4 {
+			t.Errorf("mixed tree mismatch: %d %d %d %d", len(tree.Locations), len(tree.Networks), len(tree.Providers), len(tree.Models))
+		}
+		if changed, err := Format(dir, false); err != nil || len(changed) != 0 {
+			t.Errorf("mixed tree should be canonical: %v %v", changed, err)
+		}
+	}
+
+	t.Log("Check loading entities of mixed formats.")
+	{
+		locations, err := LoadLocations(dir, LocationFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		networks, err := LoadNetworks(dir, NetworkFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		providers, err := LoadProviders(dir, ProviderFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		models, err := LoadModels(dir, ModelFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locations) != 1 || len(networks) != 4 || len(providers) != 4 || len(models) != 4 {
+			t.Errorf("mixed entities mismatch: %d %d %d %d", len(locations), len(networks), len(providers), len(models))
+		}
+	}
+}
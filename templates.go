@@ -0,0 +1,186 @@
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Template names, the metadata file templates are used by the String methods and can also be
+// rendered via the registry, the others are only available for rendering.
+const (
+	LocationTemplate   = "location"
+	NetworkTemplate    = "network"
+	ProviderTemplate   = "provider"
+	ModelTemplate      = "model"
+	FrequencyTemplate  = "frequency"
+	FieldSheetTemplate = "fieldsheet"
+	SiteReportTemplate = "report"
+)
+
+// TemplateFuncs returns the helper functions available to all templates.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"Lines":       Lines,
+		"LatLon":      LatLon,
+		"Float":       Float,
+		"Escape":      Escape,
+		"Key":         Key,
+		"Quote":       Quote,
+		"Join":        strings.Join,
+		"Upper":       strings.ToUpper,
+		"Lower":       strings.ToLower,
+		"Trim":        strings.TrimSpace,
+		"Default":     Default,
+		"DateTime":    DateTime,
+		"DateTimePtr": DateTimePtr,
+		"Cell":        Cell,
+		"Fixed":       Fixed,
+	}
+}
+
+// Quote formats text as a TOML basic string.
+func Quote(text string) string {
+	return "\"" + Escape(text) + "\""
+}
+
+// Cell formats text for use in a Markdown table cell.
+func Cell(text string) string {
+	if text == "" {
+		return "-"
+	}
+	return strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ").Replace(text)
+}
+
+// Fixed formats an optional value with the given number of decimal places.
+func Fixed(places int, f *float64) string {
+	if f == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*f, 'f', places, 64)
+}
+
+// Default returns the text pointed to, or the fallback if there is none.
+func Default(fallback string, text *string) string {
+	if text == nil {
+		return fallback
+	}
+	return *text
+}
+
+// Templates is a registry of precompiled output templates.
+type Templates struct {
+	mu        sync.RWMutex
+	funcs     template.FuncMap
+	templates map[string]*template.Template
+}
+
+// fileTemplates are the canonical TOML layouts of the metadata files.
+var fileTemplates = map[string]string{
+	LocationTemplate:  locationTemplate,
+	NetworkTemplate:   networkTemplate,
+	ProviderTemplate:  providerTemplate,
+	ModelTemplate:     modelTemplate,
+	FrequencyTemplate: frequencyTemplate,
+}
+
+// NewTemplates returns a registry holding the default metadata file, field sheet and site report templates.
+func NewTemplates() *Templates {
+	t := Templates{
+		funcs:     TemplateFuncs(),
+		templates: make(map[string]*template.Template),
+	}
+
+	defaults := map[string]string{
+		FieldSheetTemplate: fieldSheetTemplate,
+		SiteReportTemplate: siteReportTemplate,
+	}
+	for k, v := range fileTemplates {
+		defaults[k] = v
+	}
+	for k, v := range defaults {
+		if err := t.Register(k, v); err != nil {
+			panic(err)
+		}
+	}
+
+	return &t
+}
+
+// Funcs adds extra helper functions to the registry, they are available to templates registered afterwards.
+func (t *Templates) Funcs(funcs template.FuncMap) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for k, v := range funcs {
+		t.funcs[k] = v
+	}
+}
+
+// Register compiles and stores a template under the given name, replacing any existing template.
+func (t *Templates) Register(name, text string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tmpl, err := template.New(name).Funcs(t.funcs).Parse(text)
+	if err != nil {
+		return err
+	}
+	t.templates[name] = tmpl
+
+	return nil
+}
+
+// Execute renders the value using the named template.
+func (t *Templates) Execute(name string, v interface{}) (string, error) {
+	t.mu.RLock()
+	tmpl, ok := t.templates[name]
+	t.mu.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("unknown template: %s", name)
+	}
+
+	var doc bytes.Buffer
+	if err := tmpl.Execute(&doc, v); err != nil {
+		return "", err
+	}
+
+	return doc.String(), nil
+}
+
+// DefaultTemplates is the registry used by Render, along with the FieldSheet and Markdown methods.
+var DefaultTemplates = NewTemplates()
+
+// RegisterTemplate replaces, or adds, one of the default rendering templates. The metadata files are
+// always stored using their canonical templates.
+func RegisterTemplate(name, text string) error {
+	return DefaultTemplates.Register(name, text)
+}
+
+// Render formats the value using the named template from the default registry.
+func Render(name string, v interface{}) (string, error) {
+	return DefaultTemplates.Execute(name, v)
+}
+
+// canonicalTemplates are used when storing metadata files and can not be replaced.
+var canonicalTemplates = func() map[string]*template.Template {
+	templates := make(map[string]*template.Template)
+	for k, v := range fileTemplates {
+		templates[k] = template.Must(template.New(k).Funcs(TemplateFuncs()).Parse(v))
+	}
+	return templates
+}()
+
+// mustExecute formats the value using the named canonical file template, these are fixed so any
+// failure is a programming error.
+func mustExecute(name string, v interface{}) string {
+	var doc bytes.Buffer
+	if err := canonicalTemplates[name].Execute(&doc, v); err != nil {
+		panic(err)
+	}
+	return doc.String()
+}
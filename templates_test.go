@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+
+	templates := NewTemplates()
+
+	t.Log("Check default templates.")
+	{
+		s, err := templates.Execute(LocationTemplate, testLocation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != testLocation.String() {
+			t.Errorf("default template mismatch: [\n%s\n]", SimpleDiff(s, testLocation.String()))
+		}
+		if _, err := templates.Execute("unknown", testLocation); err == nil {
+			t.Error("unknown templates should fail")
+		}
+	}
+
+	t.Log("Check custom templates.")
+	{
+		if err := templates.Register(LocationTemplate, `{{.Id}} {{Upper .Name}} [{{Join .Tags "|"}}] {{Default "none" .Access | Lines | len}}`); err != nil {
+			t.Fatal(err)
+		}
+		s, err := templates.Execute(LocationTemplate, testLocation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "location A LOCATION NAME [ABC|DEF] 2"; s != expected {
+			t.Errorf("custom template mismatch: \"%s\" != \"%s\"", s, expected)
+		}
+		if DefaultTemplates == templates || testLocation.String() == s {
+			t.Error("custom templates should not alter the defaults")
+		}
+		if err := templates.Register(LocationTemplate, `{{.Id`); err == nil {
+			t.Error("invalid templates should fail")
+		}
+	}
+
+	t.Log("Check custom template functions.")
+	{
+		templates.Funcs(map[string]interface{}{
+			"Greeting": func(s string) string { return "Kia ora " + s },
+		})
+		if err := templates.Register("greeting", `{{Greeting .Name}}`); err != nil {
+			t.Fatal(err)
+		}
+		if s, err := templates.Execute("greeting", testLocation); err != nil || s != "Kia ora A Location Name" {
+			t.Errorf("custom template function mismatch: %s %v", s, err)
+		}
+	}
+}
+
+func TestTemplates_Render(t *testing.T) {
+
+	defer RegisterTemplate(LocationTemplate, locationTemplate)
+
+	t.Log("Check custom rendering does not alter stored files.")
+	{
+		stored := testLocation.String()
+		if err := RegisterTemplate(LocationTemplate, `{{.Id}}`); err != nil {
+			t.Fatal(err)
+		}
+		if s, err := Render(LocationTemplate, testLocation); err != nil || s != "location" {
+			t.Errorf("custom render mismatch: %s %v", s, err)
+		}
+		if s := testLocation.String(); s != stored {
+			t.Errorf("stored text altered by custom template: [\n%s\n]", SimpleDiff(s, stored))
+		}
+	}
+
+	t.Log("Check failed renders return an error.")
+	{
+		if err := RegisterTemplate(LocationTemplate, `{{.Unknown}}`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Render(LocationTemplate, testLocation); err == nil {
+			t.Error("expected a render error")
+		}
+	}
+}
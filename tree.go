@@ -0,0 +1,224 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Standard metadata file names.
+const (
+	LocationFile   = "location.toml"
+	NetworkFile    = "network.toml"
+	ProviderFile   = "provider.toml"
+	ModelFile      = "model.toml"
+	AssetFile      = "assets.csv"
+	RadioFile      = "radios.csv"
+	EquipmentFile  = "equipment.csv"
+	SensorFile     = "sensors.csv"
+	DataloggerFile = "dataloggers.csv"
+	FirmwareFile   = "firmware.csv"
+	FrequencyFile  = "frequencies.toml"
+)
+
+// Tree holds a complete snapshot of the metadata found below a root directory.
+type Tree struct {
+	Locations   []Location         `json:"locations,omitempty"`
+	Networks    []Network          `json:"networks,omitempty"`
+	Providers   []Provider         `json:"providers,omitempty"`
+	Models      []Model            `json:"models,omitempty"`
+	Assets      AssetList          `json:"assets,omitempty"`
+	Radios      RadioInstalls      `json:"radios,omitempty"`
+	Equipment   EquipmentInstalls  `json:"equipment,omitempty"`
+	Sensors     SensorInstalls     `json:"sensors,omitempty"`
+	Dataloggers DataloggerInstalls `json:"dataloggers,omitempty"`
+	Firmware    FirmwareInstalls   `json:"firmware,omitempty"`
+	Frequencies []FrequencyPlan    `json:"frequencies,omitempty"`
+}
+
+// LoadTree loads all the standard metadata files found below the given directory. Location, network,
+// provider and model files may also be stored as JSON or YAML.
+func LoadTree(dirname string) (*Tree, error) {
+	var paths []string
+	files := make(map[string]watchedFile)
+
+	err := filepath.Walk(dirname, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || !isMetadataFile(filepath.Base(path)) {
+			return nil
+		}
+		v, err := loadMetadataFile(path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+		files[path] = watchedFile{value: v}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTree(paths, files), nil
+}
+
+// Location returns the location with the given id.
+func (t *Tree) Location(id string) (*Location, bool) {
+	for i := range t.Locations {
+		if t.Locations[i].Id == id {
+			return &t.Locations[i], true
+		}
+	}
+	return nil, false
+}
+
+// Network returns the network associated with the given location id.
+func (t *Tree) Network(location string) (*Network, bool) {
+	for i := range t.Networks {
+		if t.Networks[i].Location == location {
+			return &t.Networks[i], true
+		}
+	}
+	return nil, false
+}
+
+// Installations returns all the radio, equipment, sensor and datalogger installations.
+func (t *Tree) Installations() Installations {
+	var ii Installations
+	for _, l := range []Installer{t.Radios, t.Equipment, t.Sensors, t.Dataloggers} {
+		ii = append(ii, l.Installations()...)
+	}
+	return ii
+}
+
+// Validate checks the internal consistency of the tree and returns a list of any problems found.
+func (t *Tree) Validate() []error {
+	var errs []error
+
+	locations := make(map[string]bool)
+	for _, l := range t.Locations {
+		if locations[l.Id] {
+			errs = append(errs, fmt.Errorf("duplicate location: %s", l.Id))
+		}
+		locations[l.Id] = true
+	}
+	for _, l := range t.Locations {
+		for _, k := range l.Links {
+			if !locations[k.Id] {
+				errs = append(errs, fmt.Errorf("location %s: unknown linked location: %s", l.Id, k.Id))
+			}
+		}
+	}
+
+	networks := make(map[string]bool)
+	devices := make(map[string]string)
+	addresses := make(map[string]string)
+	for _, n := range t.Networks {
+		if networks[n.Location] {
+			errs = append(errs, fmt.Errorf("duplicate network: %s", n.Location))
+		}
+		networks[n.Location] = true
+
+		if !locations[n.Location] {
+			errs = append(errs, fmt.Errorf("network %s: unknown location", n.Location))
+		}
+		for _, d := range n.Devices {
+			if l, ok := devices[d.Name]; ok {
+				errs = append(errs, fmt.Errorf("network %s: duplicate device %s, also in %s", n.Location, d.Name, l))
+			}
+			devices[d.Name] = n.Location
+
+			var aa []IPAddress
+			if d.Address != nil {
+				aa = append(aa, *d.Address)
+			}
+			for _, a := range append(aa, d.Aliases...) {
+				if o, ok := addresses[a.IP.String()]; ok {
+					errs = append(errs, fmt.Errorf("network %s: device %s duplicate address %s, also used by %s", n.Location, d.Name, a.IP, o))
+				}
+				addresses[a.IP.String()] = d.Name
+			}
+		}
+	}
+
+	if _, err := NewModelCatalog(t.Models); err != nil {
+		errs = append(errs, err)
+	}
+
+	// frequency keys are only checked once a plan has been given
+	if keys, err := NewFrequencyKeys(t.Frequencies); err != nil {
+		errs = append(errs, err)
+	} else if len(keys) > 0 {
+		for _, l := range t.Locations {
+			for _, k := range l.Links {
+				if k.Key == nil {
+					continue
+				}
+				if _, ok := keys[*k.Key]; !ok {
+					errs = append(errs, fmt.Errorf("location %s: link %s unknown frequency key: %s", l.Id, k.Id, *k.Key))
+				}
+			}
+		}
+		for _, r := range t.Radios {
+			if _, ok := keys[RadioKey(r)]; !ok {
+				errs = append(errs, fmt.Errorf("radio %s %s at %s: unknown frequency key: %s", r.Model, r.Serial, r.Location, RadioKey(r)))
+			}
+		}
+	}
+
+	// service labels are only checked once providers have been given
+	index, dups := NewServiceIndex(t.Providers)
+	errs = append(errs, dups...)
+	if len(index) > 0 {
+		for _, l := range t.Locations {
+			for _, s := range l.Services {
+				if _, ok := index[s]; !ok {
+					errs = append(errs, fmt.Errorf("location %s: unknown service: %s", l.Id, s))
+				}
+			}
+		}
+	}
+
+	// values which can not be stored as TOML
+	for _, l := range t.Locations {
+		for _, n := range nonFinite(l) {
+			errs = append(errs, fmt.Errorf("location %s: invalid %s value", l.Id, n))
+		}
+	}
+	for _, m := range t.Models {
+		for _, n := range nonFinite(m) {
+			errs = append(errs, fmt.Errorf("model %s: invalid %s value", m.Name, n))
+		}
+	}
+	for _, p := range t.Providers {
+		for _, n := range nonFinite(p) {
+			errs = append(errs, fmt.Errorf("provider %s: invalid %s value", p.Name, n))
+		}
+	}
+	for _, p := range t.Frequencies {
+		for _, n := range nonFinite(p) {
+			errs = append(errs, fmt.Errorf("frequency plan: invalid %s value", n))
+		}
+	}
+
+	for _, i := range t.Installations() {
+		if i.Stop.Before(i.Start) {
+			errs = append(errs, fmt.Errorf("installation %s %s at %s: stops before it starts", i.Model, i.Serial, i.Location))
+		}
+	}
+
+	return errs
+}
+
+// Installed returns the installations in place at the given time, sorted by location, model and serial number.
+func (t *Tree) Installed(at time.Time) Installations {
+	var ii Installations
+	for _, i := range t.Installations() {
+		if i.Installed(at) {
+			ii = append(ii, i)
+		}
+	}
+	sort.Sort(ii)
+	return ii
+}
@@ -0,0 +1,127 @@
+package metadata
+
+import (
+	"math"
+	"net"
+	"testing"
+)
+
+func TestTree_LoadTree(t *testing.T) {
+
+	tree, err := LoadTree("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check loading metadata tree.")
+	{
+		if len(tree.Locations) != 1 || tree.Locations[0].String() != testLocation.String() {
+			t.Error("tree location mismatch")
+		}
+		if len(tree.Networks) != 1 || tree.Networks[0].String() != testNetwork.String() {
+			t.Error("tree network mismatch")
+		}
+		if len(tree.Providers) != 1 || tree.Providers[0].String() != testProvider.String() {
+			t.Error("tree provider mismatch")
+		}
+		if len(tree.Models) != 1 || tree.Models[0].String() != testModel.String() {
+			t.Error("tree model mismatch")
+		}
+		if Strings(tree.Equipment) != Strings(testEquipmentInstalls) {
+			t.Errorf("tree equipment mismatch: [\n%s\n]", SimpleDiff(Strings(tree.Equipment), Strings(testEquipmentInstalls)))
+		}
+		if Strings(tree.Firmware) != Strings(testFirmwareInstalls) {
+			t.Errorf("tree firmware mismatch: [\n%s\n]", SimpleDiff(Strings(tree.Firmware), Strings(testFirmwareInstalls)))
+		}
+		if n := len(tree.Installed(MustParseTime("2012-06-01T00:00:00Z"))); n != 7 {
+			t.Errorf("tree installed mismatch: %d != 7", n)
+		}
+	}
+
+	t.Log("Check validating metadata tree.")
+	{
+		errs := tree.Validate()
+		expected := []string{
+			"location location: unknown linked location: somewhere",
+			"location location: unknown linked location: else",
+			"network network: unknown location",
+			"location location: link else unknown frequency key: Key 2",
+			"location location: unknown service: Test 1 Service",
+			"location location: unknown service: Test 2 Service",
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("tree validation mismatch: %v", errs)
+		}
+		for i := range expected {
+			if errs[i].Error() != expected[i] {
+				t.Errorf("tree validation mismatch: \"%s\" != \"%s\"", errs[i], expected[i])
+			}
+		}
+	}
+
+	t.Log("Check metadata tree address lookups.")
+	{
+		matches := tree.LookupIP(net.ParseIP("192.168.192.2"))
+		if len(matches) != 2 || matches[0].Kind != "alias" || matches[0].Name != "test1-network" || matches[1].Kind != "runnet" {
+			t.Errorf("tree address lookup mismatch: %v", matches)
+		}
+		matches = tree.LookupIP(net.ParseIP("10.52.1.1"))
+		if len(matches) != 1 || matches[0].Kind != "range" || matches[0].Network != "10.52.0.0/16" {
+			t.Errorf("tree address lookup mismatch: %v", matches)
+		}
+	}
+}
+
+func TestTree_ValidateNonFinite(t *testing.T) {
+
+	t.Log("Check validating values which can not be stored.")
+	{
+		tree := &Tree{
+			Locations: []Location{{Id: "A", Elevation: &[]float64{math.Inf(1)}[0]}},
+			Models: []Model{{Name: "M", Versions: map[string]Version{
+				"v1": {Name: "V1", Power: &[]float64{math.NaN()}[0]},
+			}}},
+		}
+		errs := tree.Validate()
+		expected := []string{
+			"location A: invalid elevation value",
+			"model M: invalid version.v1.power value",
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("tree validation mismatch: %v", errs)
+		}
+		for i := range expected {
+			if errs[i].Error() != expected[i] {
+				t.Errorf("tree validation mismatch: \"%s\" != \"%s\"", errs[i], expected[i])
+			}
+		}
+	}
+}
+
+func TestTree_ValidateServices(t *testing.T) {
+
+	t.Log("Check validating duplicate and unknown services.")
+	{
+		tree := &Tree{
+			Locations: []Location{{Id: "A", Services: []string{"S1", "S3"}}},
+			Providers: []Provider{
+				{Name: "P1", Services: []Service{{Name: "S1"}, {Name: "S2"}}},
+				{Name: "P2", Services: []Service{{Name: "S1"}, {Name: "S2"}}},
+			},
+		}
+		errs := tree.Validate()
+		expected := []string{
+			"provider P2: duplicate service S1, also offered by P1",
+			"provider P2: duplicate service S2, also offered by P1",
+			"location A: unknown service: S3",
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("tree validation mismatch: %v", errs)
+		}
+		for i := range expected {
+			if errs[i].Error() != expected[i] {
+				t.Errorf("tree validation mismatch: \"%s\" != \"%s\"", errs[i], expected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package metadata
+
+import (
+	"sort"
+	"time"
+)
+
+// Utilisation summarises how a single equipment model is being used.
+type Utilisation struct {
+	Model    string  `csv:"Model Name"`
+	Units    int32   `csv:"Units"`
+	Deployed int32   `csv:"Deployed"`
+	Spare    int32   `csv:"Spare"`
+	Never    int32   `csv:"Never Installed"`
+	Mean     float64 `csv:"Mean Deployment Days"`
+}
+
+type UtilisationList []Utilisation
+
+func (u UtilisationList) List() {}
+
+func (u UtilisationList) Len() int           { return len(u) }
+func (u UtilisationList) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u UtilisationList) Less(i, j int) bool { return u[i].Model < u[j].Model }
+
+type unit struct {
+	model  string
+	serial string
+}
+
+// FleetUtilisation combines the asset list with the given install lists to build a per model
+// report of units, those deployed at the given time, spares, those never installed and the
+// mean deployment duration in days. The units are those in the asset list together with any
+// found only in the install lists, so unregistered equipment is still reported. The mean is
+// the total time each unit has been installed, averaged over the units which have been installed,
+// and installations still in place are measured up to the given time.
+func FleetUtilisation(assets AssetList, at time.Time, lists ...Installer) UtilisationList {
+
+	units := make(map[unit]bool)
+	for _, a := range assets {
+		units[unit{a.Model, a.Serial}] = true
+	}
+
+	installed := make(map[unit]bool)
+	deployed := make(map[unit]bool)
+
+	durations := make(map[unit]time.Duration)
+
+	for _, l := range lists {
+		for _, i := range l.Installations() {
+			u := unit{i.Model, i.Serial}
+
+			units[u] = true
+			installed[u] = true
+
+			if i.Installed(at) {
+				deployed[u] = true
+			}
+			// radio installs have no times and so can't be measured
+			if i.Start.IsZero() || i.Start.After(at) {
+				continue
+			}
+
+			stop := i.Stop
+			if stop.After(at) {
+				stop = at
+			}
+			durations[u] += stop.Sub(i.Start)
+		}
+	}
+
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	for u, d := range durations {
+		totals[u.model] += d
+		counts[u.model]++
+	}
+
+	models := make(map[string]*Utilisation)
+	for u := range units {
+		m, ok := models[u.model]
+		if !ok {
+			m = &Utilisation{Model: u.model}
+			models[u.model] = m
+		}
+		m.Units++
+		switch {
+		case deployed[u]:
+			m.Deployed++
+		case !installed[u]:
+			m.Never++
+			m.Spare++
+		default:
+			m.Spare++
+		}
+	}
+
+	var list UtilisationList
+	for k, m := range models {
+		if n, ok := counts[k]; ok && n > 0 {
+			m.Mean = totals[k].Hours() / 24.0 / float64(n)
+		}
+		list = append(list, *m)
+	}
+	sort.Sort(list)
+
+	return list
+}
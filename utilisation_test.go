@@ -0,0 +1,26 @@
+package metadata
+
+import (
+	"testing"
+)
+
+func TestFleetUtilisation(t *testing.T) {
+	t.Log("Check fleet utilisation report.")
+	{
+		assets := append(AssetList{}, testAssetList...)
+		assets = append(assets, Asset{Model: "Model #3", Serial: "Serial #3", Asset: "Asset #3"})
+
+		report := FleetUtilisation(assets, MustParseTime("2012-06-01T00:00:00Z"), testEquipmentInstalls, testDataloggerInstalls)
+
+		expected := UtilisationList{
+			Utilisation{Model: "Model", Units: 2, Deployed: 1, Spare: 1, Mean: 623.5},
+			Utilisation{Model: "Model #1", Units: 1, Deployed: 1, Mean: 517},
+			Utilisation{Model: "Model #2", Units: 1, Deployed: 1, Mean: 517},
+			Utilisation{Model: "Model #3", Units: 1, Spare: 1, Never: 1},
+		}
+
+		if Strings(report) != Strings(expected) {
+			t.Errorf("fleet utilisation mismatch: [\n%s\n]", SimpleDiff(Strings(report), Strings(expected)))
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type watchedFile struct {
+	modified time.Time
+	size     int64
+	value    interface{}
+}
+
+// Watcher maintains a metadata tree snapshot, reloading it when any of the underlying files change.
+// Changes are found by polling, only files which have been added or modified are parsed again. New
+// snapshots are validated before they replace the current snapshot, if they introduce any problems
+// not already found in the current snapshot they are rejected and the current snapshot is kept.
+type Watcher struct {
+	dirname  string
+	onChange func(old, tree *Tree)
+	onError  func(error)
+
+	// Validate is used to check snapshots, it defaults to Tree.Validate.
+	Validate func(tree *Tree) []error
+
+	check    sync.Mutex
+	paths    []string
+	files    map[string]watchedFile
+	problems map[string]bool
+
+	mu   sync.RWMutex
+	tree *Tree
+}
+
+// ValidateTree returns an error summarising any problems found when validating the tree.
+func ValidateTree(tree *Tree) error {
+	errs := tree.Validate()
+	if len(errs) == 0 {
+		return nil
+	}
+	var s []string
+	for _, e := range errs {
+		s = append(s, e.Error())
+	}
+	return fmt.Errorf("found %d problems: %s", len(errs), strings.Join(s, "; "))
+}
+
+// NewWatcher loads the metadata tree below the given directory, an error is only returned if the tree
+// cannot be loaded. The optional onChange callback is called with the old and new snapshots whenever the
+// current snapshot is replaced, and the optional onError callback is given any problems found with the
+// initial tree as well as any later loading or validation failures.
+func NewWatcher(dirname string, onChange func(old, tree *Tree), onError func(error)) (*Watcher, error) {
+	w := Watcher{
+		dirname:  dirname,
+		onChange: onChange,
+		onError:  onError,
+		Validate: (*Tree).Validate,
+		files:    make(map[string]watchedFile),
+	}
+	if _, err := w.Check(); err != nil {
+		return nil, err
+	}
+	if len(w.problems) > 0 && onError != nil {
+		onError(ValidateTree(w.tree))
+	}
+	return &w, nil
+}
+
+// Tree returns the current metadata tree snapshot.
+func (w *Watcher) Tree() *Tree {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.tree
+}
+
+// Check scans the metadata directory once, returning whether a new snapshot has been swapped in.
+func (w *Watcher) Check() (bool, error) {
+	w.check.Lock()
+	defer w.check.Unlock()
+
+	var paths []string
+	files := make(map[string]watchedFile)
+
+	var changed bool
+	err := filepath.Walk(w.dirname, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return nil
+		}
+		if !isMetadataFile(filepath.Base(path)) {
+			return nil
+		}
+		paths = append(paths, path)
+		if f, ok := w.files[path]; ok && f.modified.Equal(fi.ModTime()) && f.size == fi.Size() {
+			files[path] = f
+			return nil
+		}
+		v, err := loadMetadataFile(path)
+		if err != nil {
+			return err
+		}
+		files[path] = watchedFile{modified: fi.ModTime(), size: fi.Size(), value: v}
+		changed = true
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range w.paths {
+		if _, ok := files[p]; !ok {
+			changed = true
+		}
+	}
+	if len(paths) != len(w.paths) {
+		changed = true
+	}
+	if !changed && w.tree != nil {
+		return false, nil
+	}
+
+	tree := buildTree(paths, files)
+
+	problems := make(map[string]bool)
+	if w.Validate != nil {
+		var found []string
+		for _, e := range w.Validate(tree) {
+			if w.tree != nil && !w.problems[e.Error()] {
+				found = append(found, e.Error())
+			}
+			problems[e.Error()] = true
+		}
+		if len(found) > 0 {
+			return false, fmt.Errorf("found %d new problems: %s", len(found), strings.Join(found, "; "))
+		}
+	}
+
+	w.paths, w.files, w.problems = paths, files, problems
+
+	w.mu.Lock()
+	old := w.tree
+	w.tree = tree
+	w.mu.Unlock()
+
+	if w.onChange != nil && old != nil {
+		w.onChange(old, tree)
+	}
+
+	return true, nil
+}
+
+// Run polls the metadata directory at the given interval until the done channel is closed, any
+// loading or validation errors are passed to the onError callback.
+func (w *Watcher) Run(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if _, err := w.Check(); err != nil && w.onError != nil {
+				w.onError(err)
+			}
+		}
+	}
+}
+
+// metadataName returns the standard name of a metadata file, allowing
+// for the alternative JSON and YAML storage formats.
+func metadataName(name string) string {
+	ext := filepath.Ext(name)
+	switch strings.ToLower(ext) {
+	case ".json", ".yaml", ".yml":
+		switch base := strings.TrimSuffix(name, ext) + TOMLFormat; base {
+		case LocationFile, NetworkFile, ProviderFile, ModelFile, FrequencyFile:
+			return base
+		}
+	}
+	return name
+}
+
+func isMetadataFile(name string) bool {
+	switch metadataName(name) {
+	case LocationFile, NetworkFile, ProviderFile, ModelFile, FrequencyFile:
+		return true
+	case AssetFile, RadioFile, EquipmentFile, SensorFile, DataloggerFile, FirmwareFile:
+		return true
+	default:
+		return false
+	}
+}
+
+func loadMetadataFile(path string) (interface{}, error) {
+	var list List
+
+	switch metadataName(filepath.Base(path)) {
+	case LocationFile:
+		return LoadLocation(path)
+	case NetworkFile:
+		return LoadNetwork(path)
+	case ProviderFile:
+		return LoadProvider(path)
+	case ModelFile:
+		return LoadModel(path)
+	case FrequencyFile:
+		return LoadFrequencyPlan(path)
+	case AssetFile:
+		list = &AssetList{}
+	case RadioFile:
+		list = &RadioInstalls{}
+	case EquipmentFile:
+		list = &EquipmentInstalls{}
+	case SensorFile:
+		list = &SensorInstalls{}
+	case DataloggerFile:
+		list = &DataloggerInstalls{}
+	case FirmwareFile:
+		list = &FirmwareInstalls{}
+	default:
+		return nil, fmt.Errorf("unknown metadata file: %s", path)
+	}
+
+	if err := LoadList(path, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// buildTree assembles the loaded files, in walk order, to match LoadTree.
+func buildTree(paths []string, files map[string]watchedFile) *Tree {
+	var t Tree
+	for _, p := range paths {
+		switch v := files[p].value.(type) {
+		case *Location:
+			t.Locations = append(t.Locations, *v)
+		case *Network:
+			t.Networks = append(t.Networks, *v)
+		case *Provider:
+			t.Providers = append(t.Providers, *v)
+		case *Model:
+			t.Models = append(t.Models, *v)
+		case *FrequencyPlan:
+			t.Frequencies = append(t.Frequencies, *v)
+		case *AssetList:
+			t.Assets = append(t.Assets, *v...)
+		case *RadioInstalls:
+			t.Radios = append(t.Radios, *v...)
+		case *EquipmentInstalls:
+			t.Equipment = append(t.Equipment, *v...)
+		case *SensorInstalls:
+			t.Sensors = append(t.Sensors, *v...)
+		case *DataloggerInstalls:
+			t.Dataloggers = append(t.Dataloggers, *v...)
+		case *FirmwareInstalls:
+			t.Firmware = append(t.Firmware, *v...)
+		}
+	}
+
+	return &t
+}
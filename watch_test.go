@@ -0,0 +1,135 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := func(loc Location, offset time.Duration) {
+		path := filepath.Join(dir, loc.Id, LocationFile)
+		if err := loc.StoreLocation(path); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(offset)
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store(Location{Id: "abc", Name: "ABC"}, 0)
+
+	var changes int
+	w, err := NewWatcher(dir, func(old, tree *Tree) { changes++ }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check watcher initial snapshot.")
+	{
+		if n := len(w.Tree().Locations); n != 1 {
+			t.Fatalf("watcher locations mismatch: %d != 1", n)
+		}
+		if ok, err := w.Check(); ok || err != nil {
+			t.Errorf("watcher should not change without file updates: %v", err)
+		}
+	}
+
+	t.Log("Check watcher file updates.")
+	{
+		store(Location{Id: "def", Name: "DEF", Links: []Link{Link{Id: "abc"}}}, time.Second)
+		if ok, err := w.Check(); !ok || err != nil {
+			t.Fatalf("watcher should change with file updates: %v", err)
+		}
+		if n := len(w.Tree().Locations); n != 2 || changes != 1 {
+			t.Errorf("watcher update mismatch: %d locations, %d changes", n, changes)
+		}
+	}
+
+	t.Log("Check watcher validation failures.")
+	{
+		old := w.Tree()
+		store(Location{Id: "def", Name: "DEF", Links: []Link{Link{Id: "xyz"}}}, 2*time.Second)
+		if ok, err := w.Check(); ok || err == nil {
+			t.Error("watcher should reject invalid snapshots")
+		}
+		if w.Tree() != old || changes != 1 {
+			t.Error("watcher should keep the old snapshot")
+		}
+	}
+}
+
+func TestWatcher_Problems(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := func(loc Location, offset time.Duration) {
+		path := filepath.Join(dir, loc.Id, LocationFile)
+		if err := loc.StoreLocation(path); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(offset)
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store(Location{Id: "abc", Name: "ABC", Links: []Link{Link{Id: "xyz"}}}, 0)
+	store(Location{Id: "def", Name: "DEF"}, 0)
+
+	var problems []error
+	w, err := NewWatcher(dir, nil, func(err error) { problems = append(problems, err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("Check watcher starts with an inconsistent tree.")
+	{
+		if w.Tree() == nil || len(w.Tree().Locations) != 2 {
+			t.Fatal("watcher should keep the initial snapshot")
+		}
+		if len(problems) != 1 {
+			t.Errorf("watcher should report initial problems: %v", problems)
+		}
+	}
+
+	t.Log("Check watcher accepts updates with existing problems.")
+	{
+		store(Location{Id: "def", Name: "Renamed"}, time.Second)
+		if ok, err := w.Check(); !ok || err != nil {
+			t.Fatalf("watcher should accept existing problems: %v", err)
+		}
+	}
+
+	t.Log("Check watcher rejects updates with new problems.")
+	{
+		store(Location{Id: "def", Name: "Renamed", Links: []Link{Link{Id: "uvw"}}}, 2*time.Second)
+		if ok, err := w.Check(); ok || err == nil {
+			t.Error("watcher should reject new problems")
+		}
+	}
+
+	t.Log("Check watcher notices removed files.")
+	{
+		if err := os.RemoveAll(filepath.Join(dir, "def")); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := w.Check(); !ok || err != nil || len(w.Tree().Locations) != 1 {
+			t.Errorf("watcher should drop removed files: %v", err)
+		}
+	}
+}